@@ -0,0 +1,110 @@
+package logrus
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CallerMode selects how the caller of a logging method is reported.
+type CallerMode uint8
+
+const (
+	// CallerBaseName reports the base name of the file, e.g. `conn.go`.
+	CallerBaseName CallerMode = iota
+	// CallerOff doesn't look up the caller at all.
+	CallerOff
+	// CallerRelative reports the path of the file relative to the root of
+	// the main module, e.g. `db/conn.go`. Files of other modules are reported
+	// with their package import path, e.g. `github.com/lib/pq/conn.go`.
+	CallerRelative
+	// CallerFull reports the full path of the file.
+	CallerFull
+)
+
+// Caller returns the caller of the entry as `file:line`, preceded by the
+// function name and a space if it was recorded.
+func (entry *Entry) Caller() string {
+	if entry.FileName == "" {
+		return ""
+	}
+	caller := entry.FileName + ":" + strconv.Itoa(entry.Line)
+	if entry.Func != "" {
+		caller = entry.Func + " " + caller
+	}
+	return caller
+}
+
+func (entry *Entry) setCaller(mode CallerMode, withFunc bool, pc uintptr, file string, line int, ok bool) {
+	if !ok {
+		entry.FileName = "???"
+		entry.Line = 1
+		return
+	}
+
+	var function string
+	if withFunc || mode == CallerRelative {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			function = fn.Name()
+		}
+	}
+
+	switch mode {
+	case CallerFull:
+		entry.FileName = file
+	case CallerRelative:
+		entry.FileName = relativePath(function, file)
+	default:
+		entry.FileName = file[strings.LastIndex(file, "/")+1:]
+	}
+	entry.Line = line
+	if withFunc {
+		entry.Func = function
+	}
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModule     string
+)
+
+// relativePath returns file relative to the main module root, based on the
+// import path of the package of function.
+func relativePath(function, file string) string {
+	base := file[strings.LastIndex(file, "/")+1:]
+	// Functions of package main are named after the package name rather than
+	// its import path, which leaves nothing to go by.
+	pkg := packagePath(function)
+	if pkg == "" || pkg == "main" {
+		return base
+	}
+
+	mainModuleOnce.Do(func() {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			mainModule = info.Main.Path
+		}
+	})
+	if mainModule != "" {
+		if pkg == mainModule {
+			return base
+		}
+		if strings.HasPrefix(pkg, mainModule+"/") {
+			pkg = pkg[len(mainModule)+1:]
+		}
+	}
+	return pkg + "/" + base
+}
+
+// packagePath returns the import path of the package of a function name as
+// returned by runtime.FuncForPC, e.g. `github.com/lib/pq` for
+// `github.com/lib/pq.(*conn).Query`.
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return function[:slash+1+dot]
+}
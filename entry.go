@@ -2,10 +2,9 @@ package logrus
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"runtime"
-	"strings"
 	"sync"
 	"time"
 )
@@ -23,6 +22,10 @@ func init() {
 // Defines the key when adding errors using WithError.
 var ErrorKey = "error"
 
+// Defines the key of the name of the logger, for entries of a logger returned
+// by Named.
+var LoggerNameKey = "logger"
+
 // An entry is the final or intermediate Logrus logging entry. It contains all
 // the fields passed with WithField{,s}. It's finally logged when Debug, Info,
 // Warn, Error, Fatal or Panic is called on it. These objects can be reused and
@@ -36,34 +39,52 @@ type Entry struct {
 	// Time at which the log entry was created
 	Time time.Time
 
-	// Level the log entry was logged at: Debug, Info, Warn, Error, Fatal or Panic
+	// Level the log entry was logged at: Trace, Debug, Info, Warn, Error, Fatal or Panic
 	Level Level
 
 	// Message passed to Debug, Info, Warn, Error, Fatal or Panic
 	Message string
 
-	//filename:line
-	//Location string
+	// File and line of the caller, and its function name when the logger's
+	// CallerFunc is set. Empty when the logger's CallerMode is CallerOff.
 	FileName string
 
 	Line int
 
+	Func string
+
+	// Stack trace of the entry, captured when its level is one of the logger's
+	// StackTraceLevels. Innermost frame first.
+	Stack []Frame
+
+	// Contains the context set by the user with WithContext. Hooks and
+	// formatters may read it, it is nil when no context was attached.
+	Context context.Context
+
 	// When formatter is called in entry.log(), an Buffer may be set to entry
 	Buffer *bytes.Buffer
+
+	// Program counter of the caller, set by callers of entry.log() which
+	// already know it, such as the slog handler.
+	pc uintptr
 }
 
 func NewEntry(logger *Logger) *Entry {
-	return &Entry{
+	entry := &Entry{
 		Logger: logger,
 		// Default is three fields, give a little extra room
 		Data: make(Fields, 5),
 	}
+	if logger != nil && logger.name != "" {
+		entry.Data[LoggerNameKey] = logger.name
+	}
+	return entry
 }
 
 // Returns the string representation from the reader and ultimately the
 // formatter.
 func (entry *Entry) String() (string, error) {
-	serialized, err := entry.Logger.Formatter.Format(entry)
+	serialized, err := entry.Logger.base().Formatter.Format(entry)
 	if err != nil {
 		return "", err
 	}
@@ -76,6 +97,16 @@ func (entry *Entry) WithError(err error) *Entry {
 	return entry.WithField(ErrorKey, err)
 }
 
+// Add a context to the Entry. Fields returned by the logger's context
+// extractors are added to the entry when it is logged.
+func (entry *Entry) WithContext(ctx context.Context) *Entry {
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	return &Entry{Logger: entry.Logger, Data: data, Context: ctx}
+}
+
 // Add a single field to the Entry.
 func (entry *Entry) WithField(key string, value interface{}) *Entry {
 	return entry.WithFields(Fields{key: value})
@@ -90,52 +121,111 @@ func (entry *Entry) WithFields(fields Fields) *Entry {
 	for k, v := range fields {
 		data[k] = v
 	}
-	return &Entry{Logger: entry.Logger, Data: data}
+	return &Entry{Logger: entry.Logger, Data: data, Context: entry.Context}
+}
+
+// Run the logger's context extractors against the entry context. Fields set
+// explicitly on the entry take precedence over extracted ones. The entry's Data
+// is shared with its parent, so it is copied before being extended.
+func (entry *Entry) extractContext() {
+	logger := entry.Logger.base()
+	added := logger.addedExtractors()
+	if entry.Context == nil || len(logger.ContextExtractors)+len(added) == 0 {
+		return
+	}
+	var data Fields
+	for _, extractors := range [][]ContextExtractor{logger.ContextExtractors, added} {
+		for _, extract := range extractors {
+			for k, v := range extract(entry.Context) {
+				if _, ok := entry.Data[k]; ok {
+					continue
+				}
+				if data == nil {
+					data = make(Fields, len(entry.Data)+1)
+					for k, v := range entry.Data {
+						data[k] = v
+					}
+				}
+				data[k] = v
+			}
+		}
+	}
+	if data != nil {
+		entry.Data = data
+	}
 }
 
 // This function is not declared with a pointer value because otherwise
 // race conditions will occur when using multiple goroutines
 func (entry Entry) log(depth int, level Level, msg string) {
 	var buffer *bytes.Buffer
+	logger := entry.Logger.base()
 	entry.Time = time.Now().UTC()
 	entry.Level = level
 	entry.Message = msg
 
-	_, file, line, ok := runtime.Caller(2 + depth)
-	if !ok {
-		entry.FileName = "???"
-		entry.Line = 1
-	} else {
-		slash := strings.LastIndex(file, "/")
-		if slash >= 0 {
-			entry.FileName = file[slash+1:]
+	// The caller is only looked up when it's reported or needed to apply
+	// per-file level rules.
+	if logger.CallerMode != CallerOff || entry.Logger.getVModule() != nil {
+		var (
+			pc   uintptr
+			file string
+			line int
+			ok   bool
+		)
+		if entry.pc != 0 {
+			pc, file, line, ok = pcFrame(entry.pc)
+		} else {
+			pc, file, line, ok = callerFrame(2 + depth)
+		}
+		if !entry.Logger.callerEnabled(level, pc, file) {
+			return
+		}
+		if logger.CallerMode != CallerOff {
+			entry.setCaller(logger.CallerMode, logger.CallerFunc, pc, file, line, ok)
 		}
-		entry.Line = line
 	}
-	//entry.Location = fmt.Sprintf("%s:%d", file, line)
 
-	if err := entry.Logger.Hooks.Fire(level, &entry); err != nil {
-		entry.Logger.mu.Lock()
+	if logger.isStackTraceLevel(level) {
+		if err, ok := entry.Data[ErrorKey].(error); ok {
+			entry.Stack = errorStack(err)
+		}
+		if entry.Stack == nil {
+			entry.Stack = captureStack(3 + depth)
+			if entry.pc != 0 {
+				entry.Stack = trimToPC(entry.Stack, entry.pc)
+			}
+		}
+	}
+
+	entry.extractContext()
+
+	if logger.Redactor != nil {
+		logger.Redactor.Redact(&entry)
+	}
+
+	if err := logger.Hooks.Fire(level, &entry); err != nil {
+		logger.mu.Lock()
 		fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
-		entry.Logger.mu.Unlock()
+		logger.mu.Unlock()
 	}
 	buffer = bufferPool.Get().(*bytes.Buffer)
 	buffer.Reset()
 	defer bufferPool.Put(buffer)
 	entry.Buffer = buffer
-	serialized, err := entry.Logger.Formatter.Format(&entry)
+	serialized, err := logger.Formatter.Format(&entry)
 	entry.Buffer = nil
 	if err != nil {
-		entry.Logger.mu.Lock()
+		logger.mu.Lock()
 		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
-		entry.Logger.mu.Unlock()
+		logger.mu.Unlock()
 	} else {
-		entry.Logger.mu.Lock()
-		_, err = entry.Logger.Out.Write(serialized)
+		logger.mu.Lock()
+		_, err = logger.Out.Write(serialized)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
 		}
-		entry.Logger.mu.Unlock()
+		logger.mu.Unlock()
 	}
 
 	// To avoid Entry#log() returning a value that only would make sense for
@@ -146,156 +236,278 @@ func (entry Entry) log(depth int, level Level, msg string) {
 	}
 }
 
-func (entry *Entry) Debug(args ...interface{}) {
-	if entry.Logger.Level >= DebugLevel {
-		entry.log(0, DebugLevel, fmt.Sprint(args...))
+// Log logs a message at the given level. Entries at FatalLevel then exit the
+// program and entries at PanicLevel panic, as Fatal and Panic do.
+func (entry *Entry) Log(level Level, args ...interface{}) {
+	entry.LogEx(1, level, args...)
+}
+
+func (entry *Entry) LogEx(depth int, level Level, args ...interface{}) {
+	if entry.Logger.mayLog(level) {
+		entry.log(depth, level, fmt.Sprint(args...))
+	}
+	if level == FatalLevel {
+		Exit(1)
 	}
 }
 
-func (entry *Entry) Info(args ...interface{}) {
-	if entry.Logger.Level >= InfoLevel {
-		entry.log(0, InfoLevel, fmt.Sprint(args...))
+func (entry *Entry) Logf(level Level, format string, args ...interface{}) {
+	entry.LogExf(1, level, format, args...)
+}
+
+func (entry *Entry) LogExf(depth int, level Level, format string, args ...interface{}) {
+	if entry.Logger.mayLog(level) {
+		entry.log(depth, level, fmt.Sprintf(format, args...))
+	}
+	if level == FatalLevel {
+		Exit(1)
 	}
 }
-func (entry *Entry) Warn(args ...interface{}) {
-	if entry.Logger.Level >= WarnLevel {
-		entry.log(0, WarnLevel, fmt.Sprint(args...))
+
+func (entry *Entry) Logln(level Level, args ...interface{}) {
+	entry.LogExln(1, level, args...)
+}
+
+func (entry *Entry) LogExln(depth int, level Level, args ...interface{}) {
+	if entry.Logger.mayLog(level) {
+		entry.log(depth, level, sprintlnn(args...))
 	}
+	if level == FatalLevel {
+		Exit(1)
+	}
+}
+
+// Entry Print family functions
+
+func (entry *Entry) Trace(args ...interface{}) {
+	entry.LogEx(1, TraceLevel, args...)
+}
+
+func (entry *Entry) Debug(args ...interface{}) {
+	entry.LogEx(1, DebugLevel, args...)
+}
+
+func (entry *Entry) Info(args ...interface{}) {
+	entry.LogEx(1, InfoLevel, args...)
+}
+
+func (entry *Entry) Print(args ...interface{}) {
+	entry.LogEx(1, InfoLevel, args...)
+}
+func (entry *Entry) Warn(args ...interface{}) {
+	entry.LogEx(1, WarnLevel, args...)
+}
+
+func (entry *Entry) Warning(args ...interface{}) {
+	entry.LogEx(1, WarnLevel, args...)
 }
 
 func (entry *Entry) Error(args ...interface{}) {
-	if entry.Logger.Level >= ErrorLevel {
-		entry.log(0, ErrorLevel, fmt.Sprint(args...))
-	}
+	entry.LogEx(1, ErrorLevel, args...)
 }
 
 func (entry *Entry) Fatal(args ...interface{}) {
-	if entry.Logger.Level >= FatalLevel {
-		entry.log(0, FatalLevel, fmt.Sprint(args...))
-	}
-	Exit(1)
+	entry.LogEx(1, FatalLevel, args...)
 }
 
 func (entry *Entry) Panic(args ...interface{}) {
-	if entry.Logger.Level >= PanicLevel {
-		entry.log(0, PanicLevel, fmt.Sprint(args...))
-	}
-	panic(fmt.Sprint(args...))
+	entry.LogEx(1, PanicLevel, args...)
 }
 
 //Entry Ex family functions
 
+func (entry *Entry) TraceEx(depth int, args ...interface{}) {
+	entry.LogEx(depth+1, TraceLevel, args...)
+}
+
 func (entry *Entry) DebugEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= DebugLevel {
-		entry.log(depth, DebugLevel, fmt.Sprint(args...))
-	}
+	entry.LogEx(depth+1, DebugLevel, args...)
 }
 
 func (entry *Entry) InfoEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= InfoLevel {
-		entry.log(depth, InfoLevel, fmt.Sprint(args...))
-	}
+	entry.LogEx(depth+1, InfoLevel, args...)
+}
+
+func (entry *Entry) PrintEx(depth int, args ...interface{}) {
+	entry.LogEx(depth+1, InfoLevel, args...)
 }
 
 func (entry *Entry) WarnEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= WarnLevel {
-		entry.log(depth, WarnLevel, fmt.Sprint(args...))
-	}
+	entry.LogEx(depth+1, WarnLevel, args...)
+}
+
+func (entry *Entry) WarningEx(depth int, args ...interface{}) {
+	entry.LogEx(depth+1, WarnLevel, args...)
 }
 
 func (entry *Entry) ErrorEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= ErrorLevel {
-		entry.log(depth, ErrorLevel, fmt.Sprint(args...))
-	}
+	entry.LogEx(depth+1, ErrorLevel, args...)
 }
 
 func (entry *Entry) FatalEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= FatalLevel {
-		entry.log(depth, FatalLevel, fmt.Sprint(args...))
-	}
-	Exit(1)
+	entry.LogEx(depth+1, FatalLevel, args...)
 }
 
 func (entry *Entry) PanicEx(depth int, args ...interface{}) {
-	if entry.Logger.Level >= PanicLevel {
-		entry.log(depth, PanicLevel, fmt.Sprint(args...))
-	}
-	panic(fmt.Sprint(args...))
+	entry.LogEx(depth+1, PanicLevel, args...)
 }
 
 // Entry Printf family functions
 
+func (entry *Entry) Tracef(format string, args ...interface{}) {
+	entry.LogExf(1, TraceLevel, format, args...)
+}
+
 func (entry *Entry) Debugf(format string, args ...interface{}) {
-	if entry.Logger.Level >= DebugLevel {
-		entry.DebugEx(1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(1, DebugLevel, format, args...)
 }
 
 func (entry *Entry) Infof(format string, args ...interface{}) {
-	if entry.Logger.Level >= InfoLevel {
-		entry.InfoEx(1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(1, InfoLevel, format, args...)
+}
+
+func (entry *Entry) Printf(format string, args ...interface{}) {
+	entry.LogExf(1, InfoLevel, format, args...)
 }
 
 func (entry *Entry) Warnf(format string, args ...interface{}) {
-	if entry.Logger.Level >= WarnLevel {
-		entry.WarnEx(1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(1, WarnLevel, format, args...)
+}
+
+func (entry *Entry) Warningf(format string, args ...interface{}) {
+	entry.LogExf(1, WarnLevel, format, args...)
 }
 
 func (entry *Entry) Errorf(format string, args ...interface{}) {
-	if entry.Logger.Level >= ErrorLevel {
-		entry.ErrorEx(1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(1, ErrorLevel, format, args...)
 }
 
 func (entry *Entry) Fatalf(format string, args ...interface{}) {
-	if entry.Logger.Level >= FatalLevel {
-		entry.FatalEx(1, fmt.Sprintf(format, args...))
-	}
-	Exit(1)
+	entry.LogExf(1, FatalLevel, format, args...)
 }
 
 func (entry *Entry) Panicf(format string, args ...interface{}) {
-	if entry.Logger.Level >= PanicLevel {
-		entry.PanicEx(1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(1, PanicLevel, format, args...)
 }
 
 //Entry PrintExf family functions
+func (entry *Entry) TraceExf(depth int, format string, args ...interface{}) {
+	entry.LogExf(depth+1, TraceLevel, format, args...)
+}
+
 func (entry *Entry) DebugExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= DebugLevel {
-		entry.DebugEx(depth+1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(depth+1, DebugLevel, format, args...)
 }
 
 func (entry *Entry) InfoExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= InfoLevel {
-		entry.InfoEx(depth+1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(depth+1, InfoLevel, format, args...)
+}
+
+func (entry *Entry) PrintExf(depth int, format string, args ...interface{}) {
+	entry.LogExf(depth+1, InfoLevel, format, args...)
 }
 
 func (entry *Entry) WarnExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= WarnLevel {
-		entry.WarnEx(depth+1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(depth+1, WarnLevel, format, args...)
+}
+
+func (entry *Entry) WarningExf(depth int, format string, args ...interface{}) {
+	entry.LogExf(depth+1, WarnLevel, format, args...)
 }
 
 func (entry *Entry) ErrorExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= ErrorLevel {
-		entry.ErrorEx(depth+1, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(depth+1, ErrorLevel, format, args...)
 }
 
 func (entry *Entry) FatalExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= FatalLevel {
-		entry.FatalEx(depth+1, fmt.Sprintf(format, args...))
-	}
-	Exit(1)
+	entry.LogExf(depth+1, FatalLevel, format, args...)
 }
 
 func (entry *Entry) PanicExf(depth int, format string, args ...interface{}) {
-	if entry.Logger.Level >= PanicLevel {
-		entry.PanicEx(1+depth, fmt.Sprintf(format, args...))
-	}
+	entry.LogExf(depth+1, PanicLevel, format, args...)
+}
+
+// Entry Println family functions
+
+func (entry *Entry) Traceln(args ...interface{}) {
+	entry.LogExln(1, TraceLevel, args...)
+}
+
+func (entry *Entry) Debugln(args ...interface{}) {
+	entry.LogExln(1, DebugLevel, args...)
+}
+
+func (entry *Entry) Infoln(args ...interface{}) {
+	entry.LogExln(1, InfoLevel, args...)
+}
+
+func (entry *Entry) Println(args ...interface{}) {
+	entry.LogExln(1, InfoLevel, args...)
+}
+
+func (entry *Entry) Warnln(args ...interface{}) {
+	entry.LogExln(1, WarnLevel, args...)
+}
+
+func (entry *Entry) Warningln(args ...interface{}) {
+	entry.LogExln(1, WarnLevel, args...)
+}
+
+func (entry *Entry) Errorln(args ...interface{}) {
+	entry.LogExln(1, ErrorLevel, args...)
+}
+
+func (entry *Entry) Fatalln(args ...interface{}) {
+	entry.LogExln(1, FatalLevel, args...)
+}
+
+func (entry *Entry) Panicln(args ...interface{}) {
+	entry.LogExln(1, PanicLevel, args...)
+}
+
+//Entry PrintExln family functions
+
+func (entry *Entry) TraceExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, TraceLevel, args...)
+}
+
+func (entry *Entry) DebugExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, DebugLevel, args...)
+}
+
+func (entry *Entry) InfoExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, InfoLevel, args...)
+}
+
+func (entry *Entry) PrintExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, InfoLevel, args...)
+}
+
+func (entry *Entry) WarnExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, WarnLevel, args...)
+}
+
+func (entry *Entry) WarningExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, WarnLevel, args...)
+}
+
+func (entry *Entry) ErrorExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, ErrorLevel, args...)
+}
+
+func (entry *Entry) FatalExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, FatalLevel, args...)
+}
+
+func (entry *Entry) PanicExln(depth int, args ...interface{}) {
+	entry.LogExln(depth+1, PanicLevel, args...)
+}
+
+// Sprintlnn => Sprint no newline. This is to get the behavior of how
+// fmt.Sprintln where spaces are always added between operands, regardless of
+// their type. Instead of vendoring the Sprintln implementation to spare a
+// string allocation, we do the simplest thing.
+func sprintlnn(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
 }
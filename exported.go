@@ -1,7 +1,9 @@
 package logrus
 
 import (
+	"context"
 	"io"
+	"os"
 )
 
 var (
@@ -29,16 +31,49 @@ func SetFormatter(formatter Formatter) {
 
 // SetLevel sets the standard logger level.
 func SetLevel(level Level) {
-	std.mu.Lock()
-	defer std.mu.Unlock()
-	std.Level = level
+	std.SetLevel(level)
 }
 
 // GetLevel returns the standard logger level.
 func GetLevel() Level {
-	std.mu.Lock()
-	defer std.mu.Unlock()
-	return std.Level
+	return std.GetLevel()
+}
+
+// IsLevelEnabled checks if the log level of the standard logger is greater
+// than the level param.
+func IsLevelEnabled(level Level) bool {
+	return std.IsLevelEnabled(level)
+}
+
+// IsCallerLevelEnabled checks if entries at the level param logged from the
+// calling function are logged by the standard logger, applying its per-file
+// rules.
+func IsCallerLevelEnabled(level Level) bool {
+	return std.isCallerLevelEnabled(1, level)
+}
+
+// ReopenOnSignal reopens the standard logger output every time one of the
+// given signals is received, `SIGHUP` if none is given.
+func ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	return std.ReopenOnSignal(sig...)
+}
+
+// Named returns a child of the standard logger, see `Logger.Named`.
+func Named(name string) *Logger {
+	return std.Named(name)
+}
+
+// SetLevelFor sets the level of the named child of the standard logger
+// called name, see `Logger.SetLevelFor`.
+func SetLevelFor(name string, level Level) {
+	std.SetLevelFor(name, level)
+}
+
+// RedirectStdLog makes the standard library log package log through the
+// standard logger at level. The returned function restores its previous
+// output.
+func RedirectStdLog(level Level) (restore func()) {
+	return std.RedirectStdLog(level)
 }
 
 // AddHook adds a hook to the standard logger hooks.
@@ -48,6 +83,17 @@ func AddHook(hook Hook) {
 	std.Hooks.Add(hook)
 }
 
+// AddContextExtractor adds a context extractor to the standard logger.
+func AddContextExtractor(extractor ContextExtractor) {
+	std.AddContextExtractor(extractor)
+}
+
+// WithContext creates an entry from the standard logger and attaches a
+// context to it.
+func WithContext(ctx context.Context) *Entry {
+	return std.WithContext(ctx)
+}
+
 // WithError creates an entry from the standard logger and adds an error to it, using the value defined in ErrorKey as key.
 func WithError(err error) *Entry {
 	return std.WithField(ErrorKey, err)
@@ -72,152 +118,303 @@ func WithFields(fields Fields) *Entry {
 	return std.WithFields(fields)
 }
 
+// Log logs a message at the given level on the standard logger.
+func Log(level Level, args ...interface{}) {
+	std.LogEx(1, level, args...)
+}
+
+// LogEx logs a message at the given level on the standard logger.
+func LogEx(depth int, level Level, args ...interface{}) {
+	std.LogEx(depth+1, level, args...)
+}
+
+// Logf logs a message at the given level on the standard logger.
+func Logf(level Level, format string, args ...interface{}) {
+	std.LogExf(1, level, format, args...)
+}
+
+// LogExf logs a message at the given level on the standard logger.
+func LogExf(depth int, level Level, format string, args ...interface{}) {
+	std.LogExf(depth+1, level, format, args...)
+}
+
+// Logln logs a message at the given level on the standard logger.
+func Logln(level Level, args ...interface{}) {
+	std.LogExln(1, level, args...)
+}
+
+// LogExln logs a message at the given level on the standard logger.
+func LogExln(depth int, level Level, args ...interface{}) {
+	std.LogExln(depth+1, level, args...)
+}
+
+// Trace logs a message at level Trace on the standard logger.
+func Trace(args ...interface{}) {
+	std.LogEx(1, TraceLevel, args...)
+}
+
 // Debug logs a message at level Debug on the standard logger.
 func Debug(args ...interface{}) {
-	std.DebugEx(1, args...)
+	std.LogEx(1, DebugLevel, args...)
 }
 
-// // Print logs a message at level Info on the standard logger.
-// func Print(args ...interface{}) {
-// 	std.Print(args...)
-// }
+// Print logs a message at level Info on the standard logger.
+func Print(args ...interface{}) {
+	std.LogEx(1, InfoLevel, args...)
+}
 
 // Info logs a message at level Info on the standard logger.
 func Info(args ...interface{}) {
-	std.InfoEx(1, args...)
+	std.LogEx(1, InfoLevel, args...)
 }
 
 // Warn logs a message at level Warn on the standard logger.
 func Warn(args ...interface{}) {
-	std.WarnEx(1, args...)
+	std.LogEx(1, WarnLevel, args...)
+}
+
+// Warning logs a message at level Warn on the standard logger.
+func Warning(args ...interface{}) {
+	std.LogEx(1, WarnLevel, args...)
 }
 
 // Error logs a message at level Error on the standard logger.
 func Error(args ...interface{}) {
-	std.ErrorEx(1, args...)
+	std.LogEx(1, ErrorLevel, args...)
 }
 
 // Panic logs a message at level Panic on the standard logger.
 func Panic(args ...interface{}) {
-	std.PanicEx(1, args...)
+	std.LogEx(1, PanicLevel, args...)
 }
 
 // Fatal logs a message at level Fatal on the standard logger.
 func Fatal(args ...interface{}) {
-	std.FatalEx(1, args...)
+	std.LogEx(1, FatalLevel, args...)
 }
 
 //PrintEx Family
+// TraceEx logs a message at level Trace on the standard logger.
+func TraceEx(depth int, args ...interface{}) {
+	std.LogEx(depth+1, TraceLevel, args...)
+}
+
 // Debug logs a message at level Debug on the standard logger.
 func DebugEx(depth int, args ...interface{}) {
-	std.DebugEx(depth+1, args...)
+	std.LogEx(depth+1, DebugLevel, args...)
 }
 
-// // Print logs a message at level Info on the standard logger.
-// func Print(args ...interface{}) {
-// 	std.Print(args...)
-// }
+// PrintEx logs a message at level Info on the standard logger.
+func PrintEx(depth int, args ...interface{}) {
+	std.LogEx(depth+1, InfoLevel, args...)
+}
 
 // Info logs a message at level Info on the standard logger.
 func InfoEx(depth int, args ...interface{}) {
-	std.InfoEx(depth+1, args...)
+	std.LogEx(depth+1, InfoLevel, args...)
 }
 
 // Warn logs a message at level Warn on the standard logger.
 func WarnEx(depth int, args ...interface{}) {
-	std.WarnEx(depth+1, args...)
+	std.LogEx(depth+1, WarnLevel, args...)
+}
+
+// WarningEx logs a message at level Warn on the standard logger.
+func WarningEx(depth int, args ...interface{}) {
+	std.LogEx(depth+1, WarnLevel, args...)
 }
 
 // Error logs a message at level Error on the standard logger.
 func ErrorEx(depth int, args ...interface{}) {
-	std.ErrorEx(depth+1, args...)
+	std.LogEx(depth+1, ErrorLevel, args...)
 }
 
 // Panic logs a message at level Panic on the standard logger.
 func PanicEx(depth int, args ...interface{}) {
-	std.PanicEx(depth+1, args...)
+	std.LogEx(depth+1, PanicLevel, args...)
 }
 
 // Fatal logs a message at level Fatal on the standard logger.
 func FatalEx(depth int, args ...interface{}) {
-	std.FatalEx(depth+1, args...)
+	std.LogEx(depth+1, FatalLevel, args...)
+}
+
+// Tracef logs a message at level Trace on the standard logger.
+func Tracef(format string, args ...interface{}) {
+	std.LogExf(1, TraceLevel, format, args...)
 }
 
 // Debugf logs a message at level Debug on the standard logger.
 func Debugf(format string, args ...interface{}) {
-	std.DebugExf(1, format, args...)
+	std.LogExf(1, DebugLevel, format, args...)
 }
 
 // Printf logs a message at level Info on the standard logger.
-// func Printf(format string, args ...interface{}) {
-// 	std.Printf(format, args...)
-// }
+func Printf(format string, args ...interface{}) {
+	std.LogExf(1, InfoLevel, format, args...)
+}
 
 // Infof logs a message at level Info on the standard logger.
 func Infof(format string, args ...interface{}) {
-	std.InfoExf(1, format, args...)
+	std.LogExf(1, InfoLevel, format, args...)
 }
 
 // Warnf logs a message at level Warn on the standard logger.
 func Warnf(format string, args ...interface{}) {
-	std.WarnExf(1, format, args...)
+	std.LogExf(1, WarnLevel, format, args...)
 }
 
 // Warningf logs a message at level Warn on the standard logger.
-// func Warningf(format string, args ...interface{}) {
-// 	std.WarningExf(1, format, args...)
-// }
+func Warningf(format string, args ...interface{}) {
+	std.LogExf(1, WarnLevel, format, args...)
+}
 
 // Errorf logs a message at level Error on the standard logger.
 func Errorf(format string, args ...interface{}) {
-	std.ErrorExf(1, format, args...)
+	std.LogExf(1, ErrorLevel, format, args...)
 }
 
 // Panicf logs a message at level Panic on the standard logger.
 func Panicf(format string, args ...interface{}) {
-	std.PanicExf(1, format, args...)
+	std.LogExf(1, PanicLevel, format, args...)
 }
 
 // Fatalf logs a message at level Fatal on the standard logger.
 func Fatalf(format string, args ...interface{}) {
-	std.FatalExf(1, format, args...)
+	std.LogExf(1, FatalLevel, format, args...)
+}
+
+func TraceExf(depth int, format string, args ...interface{}) {
+	std.LogExf(depth+1, TraceLevel, format, args...)
 }
 
 func DebugExf(depth int, format string, args ...interface{}) {
-	std.DebugExf(1+depth, format, args...)
+	std.LogExf(depth+1, DebugLevel, format, args...)
 }
 
-// Printf logs a message at level Info on the standard logger.
-// func Printf(format string, args ...interface{}) {
-// 	std.Printf(format, args...)
-// }
+// PrintExf logs a message at level Info on the standard logger.
+func PrintExf(depth int, format string, args ...interface{}) {
+	std.LogExf(depth+1, InfoLevel, format, args...)
+}
 
 // Infof logs a message at level Info on the standard logger.
 func InfoExf(depth int, format string, args ...interface{}) {
-	std.InfoExf(1+depth, format, args...)
+	std.LogExf(depth+1, InfoLevel, format, args...)
 }
 
 // Warnf logs a message at level Warn on the standard logger.
 func WarnExf(depth int, format string, args ...interface{}) {
-	std.WarnExf(1+depth, format, args...)
+	std.LogExf(depth+1, WarnLevel, format, args...)
 }
 
-// Warningf logs a message at level Warn on the standard logger.
-// func Warningf(format string, args ...interface{}) {
-// 	std.WarningExf(1, format, args...)
-// }
+// WarningExf logs a message at level Warn on the standard logger.
+func WarningExf(depth int, format string, args ...interface{}) {
+	std.LogExf(depth+1, WarnLevel, format, args...)
+}
 
 // Errorf logs a message at level Error on the standard logger.
 func ErrorExf(depth int, format string, args ...interface{}) {
-	std.ErrorExf(1+depth, format, args...)
+	std.LogExf(depth+1, ErrorLevel, format, args...)
 }
 
 // Panicf logs a message at level Panic on the standard logger.
 func PanicExf(depth int, format string, args ...interface{}) {
-	std.PanicExf(1+depth, format, args...)
+	std.LogExf(depth+1, PanicLevel, format, args...)
 }
 
 // Fatalf logs a message at level Fatal on the standard logger.
 func FatalExf(depth int, format string, args ...interface{}) {
-	std.FatalExf(1+depth, format, args...)
+	std.LogExf(depth+1, FatalLevel, format, args...)
+}
+
+//Println family
+
+// Traceln logs a message at level Trace on the standard logger.
+func Traceln(args ...interface{}) {
+	std.LogExln(1, TraceLevel, args...)
+}
+
+// Debugln logs a message at level Debug on the standard logger.
+func Debugln(args ...interface{}) {
+	std.LogExln(1, DebugLevel, args...)
+}
+
+// Infoln logs a message at level Info on the standard logger.
+func Infoln(args ...interface{}) {
+	std.LogExln(1, InfoLevel, args...)
+}
+
+// Println logs a message at level Info on the standard logger.
+func Println(args ...interface{}) {
+	std.LogExln(1, InfoLevel, args...)
+}
+
+// Warnln logs a message at level Warn on the standard logger.
+func Warnln(args ...interface{}) {
+	std.LogExln(1, WarnLevel, args...)
+}
+
+// Warningln logs a message at level Warn on the standard logger.
+func Warningln(args ...interface{}) {
+	std.LogExln(1, WarnLevel, args...)
+}
+
+// Errorln logs a message at level Error on the standard logger.
+func Errorln(args ...interface{}) {
+	std.LogExln(1, ErrorLevel, args...)
+}
+
+// Fatalln logs a message at level Fatal on the standard logger.
+func Fatalln(args ...interface{}) {
+	std.LogExln(1, FatalLevel, args...)
+}
+
+// Panicln logs a message at level Panic on the standard logger.
+func Panicln(args ...interface{}) {
+	std.LogExln(1, PanicLevel, args...)
+}
+
+// TraceExln logs a message at level Trace on the standard logger.
+func TraceExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, TraceLevel, args...)
+}
+
+// DebugExln logs a message at level Debug on the standard logger.
+func DebugExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, DebugLevel, args...)
+}
+
+// InfoExln logs a message at level Info on the standard logger.
+func InfoExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, InfoLevel, args...)
+}
+
+// PrintExln logs a message at level Info on the standard logger.
+func PrintExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, InfoLevel, args...)
+}
+
+// WarnExln logs a message at level Warn on the standard logger.
+func WarnExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, WarnLevel, args...)
+}
+
+// WarningExln logs a message at level Warn on the standard logger.
+func WarningExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, WarnLevel, args...)
+}
+
+// ErrorExln logs a message at level Error on the standard logger.
+func ErrorExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, ErrorLevel, args...)
+}
+
+// FatalExln logs a message at level Fatal on the standard logger.
+func FatalExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, FatalLevel, args...)
+}
+
+// PanicExln logs a message at level Panic on the standard logger.
+func PanicExln(depth int, args ...interface{}) {
+	std.LogExln(depth+1, PanicLevel, args...)
 }
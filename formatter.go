@@ -2,6 +2,36 @@ package logrus
 
 const DefaultTimestampFormat = "2006-01-02 15:04:05.000"
 
+// Default keys for the fields every entry has, see FieldMap.
+const (
+	FieldKeyTime     = "time"
+	FieldKeyMsg      = "message"
+	FieldKeyLevel    = "level"
+	FieldKeyFileName = "filename"
+	FieldKeyLine     = "line"
+	FieldKeyFunc     = "func"
+	FieldKeyCaller   = "caller"
+	FieldKeyStack    = "stack"
+)
+
+// FieldMap allows customization of the key names for the default fields, e.g.
+//
+//    FieldMap{
+//      FieldKeyTime:  "@timestamp",
+//      FieldKeyMsg:   "msg",
+//      FieldKeyLevel: "severity",
+//    }
+//
+// Keys which are not in the map keep their default name.
+type FieldMap map[string]string
+
+func (f FieldMap) resolve(key string) string {
+	if k, ok := f[key]; ok {
+		return k
+	}
+	return key
+}
+
 // The Formatter interface is used to implement a custom Formatter. It takes an
 // `Entry`. It exposes all the fields, including the default ones:
 //
@@ -26,26 +56,34 @@ type Formatter interface {
 //
 //  {"level": "info", "fields.level": 1, "msg": "hello", "time": "..."}
 //
+// The clashing fields are renamed in data, which must be a copy of the fields
+// of the entry. The keys checked are the ones the formatter writes the
+// default fields to, after remapping them with fieldMap, plus the optional
+// default keys it writes for this entry, such as those of callerKeys and
+// FieldKeyStack.
+//
 // It's not exported because it's still using Data in an opinionated way. It's to
 // avoid code duplication between the two default formatters.
-func prefixFieldClashes(data Fields) {
-	if t, ok := data["time"]; ok {
-		data["fields.time"] = t
-	}
-
-	if m, ok := data["message"]; ok {
-		data["fields.message"] = m
-	}
-
-	if l, ok := data["level"]; ok {
-		data["fields.level"] = l
-	}
-
-	if fn, ok := data["filename"]; ok {
-		data["fields.filename"] = fn
+func prefixFieldClashes(data Fields, fieldMap FieldMap, keys ...string) {
+	for _, key := range append([]string{FieldKeyTime, FieldKeyMsg, FieldKeyLevel}, keys...) {
+		key = fieldMap.resolve(key)
+		if v, ok := data[key]; ok {
+			data["fields."+key] = v
+			delete(data, key)
+		}
 	}
+}
 
-	if line, ok := data["line"]; ok {
-		data["fields.line"] = line
+// callerKeys returns the default keys the caller of entry is written to, none
+// if it isn't reported.
+func callerKeys(entry *Entry, joinCaller bool) []string {
+	switch {
+	case entry.FileName == "":
+		return nil
+	case joinCaller:
+		return []string{FieldKeyCaller}
+	case entry.Func != "":
+		return []string{FieldKeyFileName, FieldKeyLine, FieldKeyFunc}
 	}
+	return []string{FieldKeyFileName, FieldKeyLine}
 }
@@ -0,0 +1,50 @@
+package logrus
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPrefixFieldClashes(t *testing.T) {
+	clashing := Fields{"time": 1, "filename": 2, "line": 3, "func": 4, "caller": 5, "stack": 6}
+	for _, tt := range []struct {
+		name       string
+		fileName   string
+		funcName   string
+		joinCaller bool
+		prefixed   []string
+	}{
+		{"no caller", "", "", false, []string{"time"}},
+		{"file and line", "main.go", "", false, []string{"time", "filename", "line"}},
+		{"with func", "main.go", "main.main", false, []string{"time", "filename", "line", "func"}},
+		{"joined", "main.go", "main.main", true, []string{"time", "caller"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := NewEntry(New())
+			entry.Data = Fields{}
+			for k, v := range clashing {
+				entry.Data[k] = v
+			}
+			entry.FileName, entry.Line, entry.Func = tt.fileName, 1, tt.funcName
+
+			b, err := (&JSONFormatter{JoinCaller: tt.joinCaller}).Format(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal(b, &m); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]bool{}
+			for _, k := range tt.prefixed {
+				want["fields."+k] = true
+			}
+			for k := range clashing {
+				if _, ok := m["fields."+k]; ok != want["fields."+k] {
+					t.Errorf("fields.%s present: %v, want %v in %s", k, ok, want["fields."+k], b)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package gelf provides a Formatter producing GELF 1.1 messages for Graylog,
+// and a Writer sending them over UDP or TCP.
+//
+//	writer, err := gelf.NewWriter("udp", "graylog:12201")
+//	if err != nil {
+//	  panic(err)
+//	}
+//	logger.Out = writer
+//	logger.Formatter = new(gelf.Formatter)
+package gelf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pingpp/logrus"
+)
+
+const Version = "1.1"
+
+// Formatter formats entries as GELF 1.1 messages. The first line of the
+// message is sent as `short_message`, the whole message as `full_message` if
+// it spans several lines, and the fields as additional fields.
+type Formatter struct {
+	// Host is the name of the host sending the message. Defaults to
+	// os.Hostname().
+	Host string
+}
+
+// Characters allowed in the name of an additional field.
+var invalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	host := f.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	short := entry.Message
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = short[:i]
+	}
+
+	data := make(map[string]interface{}, len(entry.Data)+8)
+	for k, v := range entry.Data {
+		data[fieldName(k)] = fieldValue(v)
+	}
+
+	data["version"] = Version
+	data["host"] = host
+	data["short_message"] = short
+	if short != entry.Message {
+		data["full_message"] = entry.Message
+	}
+	data["timestamp"] = float64(entry.Time.UnixNano()/int64(1e6)) / 1e3
+	data["level"] = Level(entry.Level)
+	if entry.FileName != "" {
+		data["_file"] = entry.FileName
+		data["_line"] = entry.Line
+	}
+
+	serialized, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal fields to JSON, %v", err)
+	}
+	return serialized, nil
+}
+
+// Level maps a logrus level to the syslog severity GELF uses as level.
+func Level(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// fieldName returns the name of the additional field for key. Names which
+// would clash with `_id`, which GELF forbids, or with `_file` and `_line` are
+// prefixed with `fields.`, like prefixFieldClashes does in logrus.
+func fieldName(key string) string {
+	key = invalidFieldChars.ReplaceAllString(key, "_")
+	switch key {
+	case "id", "file", "line":
+		key = "fields." + key
+	}
+	return "_" + key
+}
+
+// fieldValue returns v as a string or a number, the only types GELF allows
+// for additional fields.
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,119 @@
+package gelf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+func TestFormatter(t *testing.T) {
+	ts := time.Date(2016, 11, 4, 10, 0, 0, 123456000, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		message  string
+		level    logrus.Level
+		data     logrus.Fields
+		fileName string
+		want     map[string]interface{}
+	}{
+		{
+			name:    "short",
+			message: "A walrus appears",
+			level:   logrus.InfoLevel,
+			want:    map[string]interface{}{"short_message": "A walrus appears", "level": 6.0},
+		},
+		{
+			name:    "multiline",
+			message: "first\nsecond",
+			level:   logrus.ErrorLevel,
+			want:    map[string]interface{}{"short_message": "first", "full_message": "first\nsecond", "level": 3.0},
+		},
+		{
+			name:    "fields",
+			message: "m",
+			level:   logrus.WarnLevel,
+			data: logrus.Fields{
+				"animal":    "walrus",
+				"size":      10,
+				"err":       errors.New("boom"),
+				"tags":      []string{"a", "b"},
+				"odd key!":  "x",
+				"dotted.ok": "y",
+			},
+			want: map[string]interface{}{
+				"short_message": "m",
+				"level":         4.0,
+				"_animal":       "walrus",
+				"_size":         10.0,
+				"_err":          "boom",
+				"_tags":         "[a b]",
+				"_odd_key_":     "x",
+				"_dotted.ok":    "y",
+			},
+		},
+		{
+			name:     "reserved",
+			message:  "m",
+			level:    logrus.DebugLevel,
+			data:     logrus.Fields{"id": 1, "file": "f", "line": "l"},
+			fileName: "main.go",
+			want: map[string]interface{}{
+				"short_message": "m",
+				"level":         7.0,
+				"_fields.id":    1.0,
+				"_fields.file":  "f",
+				"_fields.line":  "l",
+				"_file":         "main.go",
+				"_line":         42.0,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := logrus.NewEntry(logrus.New())
+			entry.Time = ts
+			entry.Level = tt.level
+			entry.Message = tt.message
+			for k, v := range tt.data {
+				entry.Data[k] = v
+			}
+			entry.FileName, entry.Line = tt.fileName, 42
+
+			b, err := (&Formatter{Host: "host"}).Format(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("invalid JSON %s: %v", b, err)
+			}
+
+			want := map[string]interface{}{"version": Version, "host": "host", "timestamp": 1478253600.123}
+			for k, v := range tt.want {
+				want[k] = v
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Format() =\n%v\nwant\n%v", got, want)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	for level, want := range map[logrus.Level]int{
+		logrus.PanicLevel: 2,
+		logrus.FatalLevel: 2,
+		logrus.ErrorLevel: 3,
+		logrus.WarnLevel:  4,
+		logrus.InfoLevel:  6,
+		logrus.DebugLevel: 7,
+		logrus.TraceLevel: 7,
+	} {
+		if got := Level(level); got != want {
+			t.Errorf("Level(%v) = %d, want %d", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// Compression is the compression applied to messages sent over UDP. GELF
+// doesn't support compression over TCP.
+type Compression int
+
+const (
+	CompressGzip Compression = iota
+	CompressZlib
+	CompressNone
+)
+
+const (
+	// ChunkSizeWAN and ChunkSizeLAN are the usual maximum sizes of a UDP
+	// datagram, depending on the network between the writer and Graylog.
+	ChunkSizeWAN = 1420
+	ChunkSizeLAN = 8154
+
+	maxChunks         = 128
+	chunkHeaderLength = 12
+)
+
+var chunkMagic = []byte{0x1e, 0x0f}
+
+// ErrTooLarge is returned for a message which needs more than 128 chunks.
+var ErrTooLarge = errors.New("gelf: message too large")
+
+// Writer sends each call to Write as one GELF message. Over UDP the message is
+// compressed and split into chunks when it's larger than ChunkSize. Over TCP
+// it's terminated with a null byte.
+type Writer struct {
+	// Compression of UDP messages, gzip by default.
+	Compression Compression
+
+	// ChunkSize is the maximum size of a UDP datagram, ChunkSizeWAN by
+	// default.
+	ChunkSize int
+
+	network string
+	addr    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewWriter connects to the GELF input at addr. The network is "udp" or
+// "tcp".
+func NewWriter(network, addr string) (*Writer, error) {
+	switch network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		return nil, errors.New("gelf: unsupported network " + network)
+	}
+
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		ChunkSize: ChunkSizeWAN,
+		network:   network,
+		addr:      addr,
+		conn:      conn,
+	}, nil
+}
+
+// Write sends p as one message. A trailing newline, as added by most
+// formatters, is removed.
+func (w *Writer) Write(p []byte) (int, error) {
+	msg := bytes.TrimSuffix(p, []byte{'\n'})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.Dial(w.network, w.addr)
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	var err error
+	if w.isUDP() {
+		err = w.writeUDP(msg)
+	} else {
+		err = w.writeTCP(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *Writer) isUDP() bool {
+	return w.network == "udp" || w.network == "udp4" || w.network == "udp6"
+}
+
+func (w *Writer) writeTCP(msg []byte) error {
+	if bytes.IndexByte(msg, 0) >= 0 {
+		return errors.New("gelf: message contains a null byte")
+	}
+	buf := make([]byte, len(msg)+1)
+	copy(buf, msg)
+
+	if _, err := w.conn.Write(buf); err != nil {
+		// Drop the connection so the next write reconnects.
+		w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) writeUDP(msg []byte) error {
+	msg, err := w.compress(msg)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := w.ChunkSize
+	if chunkSize <= chunkHeaderLength {
+		chunkSize = ChunkSizeWAN
+	}
+	if len(msg) <= chunkSize {
+		_, err = w.conn.Write(msg)
+		return err
+	}
+
+	dataSize := chunkSize - chunkHeaderLength
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > maxChunks {
+		return ErrTooLarge
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 0, chunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * dataSize
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk = append(chunk[:0], chunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*dataSize:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) compress(msg []byte) ([]byte, error) {
+	var b bytes.Buffer
+	var zw io.WriteCloser
+	switch w.Compression {
+	case CompressGzip:
+		zw = gzip.NewWriter(&b)
+	case CompressZlib:
+		zw = zlib.NewWriter(&b)
+	default:
+		return msg, nil
+	}
+
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
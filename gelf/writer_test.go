@@ -0,0 +1,250 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenUDP returns the address of a UDP listener and a channel receiving
+// its datagrams.
+func listenUDP(t *testing.T) (string, <-chan []byte) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	received := make(chan []byte, maxChunks+1)
+	go func() {
+		buf := make([]byte, 64<<10)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+	return conn.LocalAddr().String(), received
+}
+
+func receive(t *testing.T, received <-chan []byte) []byte {
+	t.Helper()
+	select {
+	case b := <-received:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+		return nil
+	}
+}
+
+// receiveMessage reassembles the chunks of a message, checking their headers,
+// and returns it with the number of chunks.
+func receiveMessage(t *testing.T, received <-chan []byte) ([]byte, int) {
+	t.Helper()
+	first := receive(t, received)
+	if !bytes.HasPrefix(first, chunkMagic) {
+		return first, 1
+	}
+
+	var id []byte
+	var chunks [][]byte
+	for b := first; ; b = receive(t, received) {
+		if len(b) < chunkHeaderLength || !bytes.HasPrefix(b, chunkMagic) {
+			t.Fatalf("invalid chunk header % x", b)
+		}
+		seq, count := int(b[10]), int(b[11])
+		if id == nil {
+			id = b[2:10]
+			chunks = make([][]byte, count)
+		}
+		if !bytes.Equal(b[2:10], id) || count != len(chunks) || seq >= count || chunks[seq] != nil {
+			t.Fatalf("chunk %d/%d of message % x, want one of %d of message % x", seq, count, b[2:10], len(chunks), id)
+		}
+		chunks[seq] = b[chunkHeaderLength:]
+
+		done := true
+		for _, c := range chunks {
+			done = done && c != nil
+		}
+		if done {
+			return bytes.Join(chunks, nil), len(chunks)
+		}
+	}
+}
+
+func decompress(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var r io.Reader
+	var err error
+	switch {
+	case bytes.HasPrefix(b, []byte{0x1f, 0x8b}):
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	case len(b) > 0 && b[0] == 0x78:
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	default:
+		return b
+	}
+	if err == nil {
+		b, err = ioutil.ReadAll(r)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// randomMessage returns a message of n bytes which doesn't compress.
+func randomMessage(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return []byte(hex.EncodeToString(b)[:n])
+}
+
+func newUDPWriter(t *testing.T, addr string, compression Compression, chunkSize int) *Writer {
+	t.Helper()
+	w, err := NewWriter("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	w.Compression = compression
+	w.ChunkSize = chunkSize
+	return w
+}
+
+func TestWriterUDP(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		compression Compression
+		size        int
+		chunks      int
+	}{
+		{"gzip", CompressGzip, 100, 1},
+		{"zlib", CompressZlib, 100, 1},
+		{"none", CompressNone, 100, 1},
+		{"gzip chunked", CompressGzip, 5000, 0},
+		{"zlib chunked", CompressZlib, 5000, 0},
+		{"none chunked", CompressNone, 5000, 5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, received := listenUDP(t)
+			w := newUDPWriter(t, addr, tt.compression, 1024+chunkHeaderLength)
+
+			msg := randomMessage(t, tt.size)
+			if n, err := w.Write(append(msg, '\n')); err != nil || n != len(msg)+1 {
+				t.Fatalf("Write() = %d, %v", n, err)
+			}
+			got, chunks := receiveMessage(t, received)
+			if tt.compression == CompressNone {
+				if got[0] == 0x1f || got[0] == 0x78 {
+					t.Error("message compressed with CompressNone")
+				}
+			} else if bytes.Equal(got, msg) {
+				t.Error("message not compressed")
+			}
+			if got = decompress(t, got); !bytes.Equal(got, msg) {
+				t.Errorf("received %q, want %q", got, msg)
+			}
+			if tt.chunks > 0 && chunks != tt.chunks {
+				t.Errorf("sent in %d chunks, want %d", chunks, tt.chunks)
+			}
+			if tt.chunks == 0 && chunks < 2 {
+				t.Errorf("sent in %d chunks, want several", chunks)
+			}
+		})
+	}
+}
+
+func TestWriterUDPMaxChunks(t *testing.T) {
+	addr, received := listenUDP(t)
+	// One byte of data per chunk.
+	w := newUDPWriter(t, addr, CompressNone, chunkHeaderLength+1)
+
+	if _, err := w.Write(randomMessage(t, maxChunks+1)); err != ErrTooLarge {
+		t.Errorf("Write() of %d chunks = %v, want ErrTooLarge", maxChunks+1, err)
+	}
+
+	msg := randomMessage(t, maxChunks)
+	if _, err := w.Write(msg); err != nil {
+		t.Fatalf("Write() of %d chunks = %v", maxChunks, err)
+	}
+	got, chunks := receiveMessage(t, received)
+	if !bytes.Equal(got, msg) || chunks != maxChunks {
+		t.Errorf("received %q in %d chunks, want %q in %d", got, chunks, msg, maxChunks)
+	}
+}
+
+func TestWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan string, 10)
+	go func() {
+		defer close(received)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			s, err := r.ReadString(0)
+			if err != nil {
+				if s != "" {
+					received <- s
+				}
+				return
+			}
+			received <- s
+		}
+	}()
+
+	w, err := NewWriter("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Compression = CompressGzip // ignored over TCP
+	for _, msg := range []string{`{"short_message":"first"}` + "\n", `{"short_message":"multi\nline"}`} {
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Write([]byte("null\x00byte")); err == nil {
+		t.Error("Write() of a message with a null byte succeeded")
+	}
+	w.Close()
+
+	var got []string
+	for s := range received {
+		got = append(got, s)
+	}
+	want := []string{`{"short_message":"first"}` + "\x00", `{"short_message":"multi\nline"}` + "\x00"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestNewWriterNetwork(t *testing.T) {
+	if _, err := NewWriter("unix", "/tmp/gelf.sock"); err == nil {
+		t.Error("NewWriter() accepted a unix socket")
+	}
+}
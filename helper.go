@@ -0,0 +1,142 @@
+package logrus
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Functions registered as logging helpers are skipped when looking up the
+// caller of a logging method, so a logging facade reports the call sites of
+// its own callers without counting frames for the `Ex` methods.
+var callerHelpers struct {
+	// Set once anything is registered, to keep the lookup cheap otherwise.
+	enabled int32
+
+	mu       sync.RWMutex
+	prefixes []string
+	funcs    map[string]bool
+	// Decisions per function name, reset when a prefix is registered.
+	cache map[string]bool
+}
+
+// RegisterHelper marks the functions matching any of prefixes as logging
+// helpers. A prefix is a package import path, e.g. `github.com/acme/log`,
+// which matches all the functions and methods of that package, or a function
+// name, e.g. `github.com/acme/log.(*Logger).Info`, which also matches its
+// closures. A prefix ending with '.' or '/' matches any function name
+// starting with it.
+func RegisterHelper(prefixes ...string) {
+	callerHelpers.mu.Lock()
+	defer callerHelpers.mu.Unlock()
+	callerHelpers.prefixes = append(callerHelpers.prefixes, prefixes...)
+	callerHelpers.cache = nil
+	atomic.StoreInt32(&callerHelpers.enabled, 1)
+}
+
+// Helper marks the calling function as a logging helper, like
+// `testing.T.Helper`. Entries logged through it report the caller of the
+// helper instead.
+func Helper() {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return
+	}
+	name := fn.Name()
+
+	callerHelpers.mu.RLock()
+	marked := callerHelpers.funcs[name]
+	callerHelpers.mu.RUnlock()
+	if marked {
+		return
+	}
+
+	callerHelpers.mu.Lock()
+	defer callerHelpers.mu.Unlock()
+	if callerHelpers.funcs == nil {
+		callerHelpers.funcs = make(map[string]bool)
+	}
+	callerHelpers.funcs[name] = true
+	atomic.StoreInt32(&callerHelpers.enabled, 1)
+}
+
+func isHelper(function string) bool {
+	callerHelpers.mu.RLock()
+	helper, cached := callerHelpers.cache[function]
+	if !cached {
+		helper = callerHelpers.funcs[function]
+	}
+	callerHelpers.mu.RUnlock()
+	if cached || helper {
+		return helper
+	}
+
+	callerHelpers.mu.Lock()
+	defer callerHelpers.mu.Unlock()
+	for _, prefix := range callerHelpers.prefixes {
+		if matchHelper(prefix, function) {
+			helper = true
+			break
+		}
+	}
+	if callerHelpers.cache == nil {
+		callerHelpers.cache = make(map[string]bool)
+	}
+	callerHelpers.cache[function] = helper
+	return helper
+}
+
+func matchHelper(prefix, function string) bool {
+	if !strings.HasPrefix(function, prefix) {
+		return false
+	}
+	if len(function) == len(prefix) {
+		return true
+	}
+	switch prefix[len(prefix)-1] {
+	case '.', '/':
+		return true
+	}
+	return function[len(prefix)] == '.'
+}
+
+// callerFrame is `runtime.Caller` skipping logging helpers. As for
+// `runtime.Caller`, a skip of 0 is the function calling callerFrame.
+func callerFrame(skip int) (pc uintptr, file string, line int, ok bool) {
+	if atomic.LoadInt32(&callerHelpers.enabled) == 0 {
+		return runtime.Caller(skip + 1)
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isHelper(frame.Function) || !more {
+			return frame.PC, frame.File, frame.Line, frame.PC != 0
+		}
+	}
+}
+
+// pcFrame returns the frame of pc, a program counter as returned by
+// runtime.Callers.
+func pcFrame(pc uintptr) (uintptr, string, int, bool) {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame.PC, frame.File, frame.Line, frame.PC != 0
+}
+
+// trimHelpers removes the logging helpers from the top of a stack trace.
+func trimHelpers(stack []Frame) []Frame {
+	if atomic.LoadInt32(&callerHelpers.enabled) == 0 {
+		return stack
+	}
+	for len(stack) > 1 && isHelper(stack[0].Func) {
+		stack = stack[1:]
+	}
+	return stack
+}
@@ -0,0 +1,155 @@
+package logrus
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+// resetHelpers forgets the helpers registered by the test once it's done.
+func resetHelpers(t *testing.T) {
+	t.Cleanup(func() {
+		callerHelpers.mu.Lock()
+		defer callerHelpers.mu.Unlock()
+		callerHelpers.prefixes = nil
+		callerHelpers.funcs = nil
+		callerHelpers.cache = nil
+		atomic.StoreInt32(&callerHelpers.enabled, 0)
+	})
+}
+
+func funcName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+func newHelperTest() (*Logger, *entryHook) {
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+	logger.CallerFunc = true
+	hook := &entryHook{}
+	logger.Hooks.Add(hook)
+	return logger, hook
+}
+
+func checkCaller(t *testing.T, hook *entryHook, line int) {
+	t.Helper()
+	if len(hook.entries) == 0 {
+		t.Fatal("nothing logged")
+	}
+	e := hook.entries[len(hook.entries)-1]
+	if e.FileName != "helper_test.go" || e.Line != line {
+		t.Errorf("caller = %s:%d, want helper_test.go:%d", e.FileName, e.Line, line)
+	}
+}
+
+func registeredInfo(logger *Logger, msg string) {
+	logger.Info(msg)
+}
+
+func registeredClosure(logger *Logger, msg string) {
+	func() {
+		logger.Info(msg)
+	}()
+}
+
+func markedInfo(logger *Logger, msg string) {
+	Helper()
+	logger.Info(msg)
+}
+
+func markedNested(logger *Logger, msg string) {
+	Helper()
+	markedInfo(logger, msg)
+}
+
+func unmarkedInfo(logger *Logger, msg string) {
+	logger.Info(msg)
+}
+
+func TestRegisterHelper(t *testing.T) {
+	resetHelpers(t)
+	logger, hook := newHelperTest()
+	RegisterHelper(funcName(registeredInfo), funcName(registeredClosure))
+
+	_, _, line, _ := runtime.Caller(0)
+	registeredInfo(logger, "wrapped")
+	checkCaller(t, hook, line+1)
+
+	_, _, line, _ = runtime.Caller(0)
+	registeredClosure(logger, "closure")
+	checkCaller(t, hook, line+1)
+
+	_, _, line, _ = runtime.Caller(0)
+	logger.Info("direct")
+	checkCaller(t, hook, line+1)
+}
+
+func TestHelper(t *testing.T) {
+	resetHelpers(t)
+	logger, hook := newHelperTest()
+
+	_, _, line, _ := runtime.Caller(0)
+	markedInfo(logger, "marked")
+	checkCaller(t, hook, line+1)
+
+	_, _, line, _ = runtime.Caller(0)
+	markedNested(logger, "nested")
+	checkCaller(t, hook, line+1)
+
+	// Wrappers not marked are reported as the caller.
+	unmarkedInfo(logger, "unmarked")
+	if e := hook.entries[len(hook.entries)-1]; e.Func != funcName(unmarkedInfo) {
+		t.Errorf("caller = %s:%d in %s, want unmarkedInfo", e.FileName, e.Line, e.Func)
+	}
+}
+
+func TestHelperStack(t *testing.T) {
+	resetHelpers(t)
+	logger, hook := newHelperTest()
+	logger.StackTraceLevels = []Level{InfoLevel}
+
+	_, _, line, _ := runtime.Caller(0)
+	markedInfo(logger, "marked")
+	if e := hook.entries[0]; len(e.Stack) == 0 || e.Stack[0].Line != line+1 {
+		t.Errorf("stack = %v, want it to start at line %d", e.Stack, line+1)
+	}
+}
+
+func TestMatchHelper(t *testing.T) {
+	for _, tt := range []struct {
+		prefix, function string
+		match            bool
+	}{
+		{"github.com/acme/log", "github.com/acme/log.Info", true},
+		{"github.com/acme/log", "github.com/acme/log.(*Logger).Info", true},
+		{"github.com/acme/log", "github.com/acme/logger.Info", false},
+		{"github.com/acme/log", "github.com/acme/log/sub.Info", false},
+		{"github.com/acme/log.(*Logger).Info", "github.com/acme/log.(*Logger).Info", true},
+		{"github.com/acme/log.(*Logger).Info", "github.com/acme/log.(*Logger).Info.func1", true},
+		{"github.com/acme/log.(*Logger).Info", "github.com/acme/log.(*Logger).Infof", false},
+		{"github.com/acme/", "github.com/acme/log/sub.Info", true},
+		{"github.com/acme/log.", "github.com/acme/log.Info", true},
+		{"log.", "log.(*Logger).Output", true},
+		{"log.", "log/slog.(*Logger).Info", false},
+		{"github.com/acme/log", "github.com/other.Info", false},
+	} {
+		if got := matchHelper(tt.prefix, tt.function); got != tt.match {
+			t.Errorf("matchHelper(%q, %q) = %v, want %v", tt.prefix, tt.function, got, tt.match)
+		}
+	}
+}
+
+func TestRegisterHelperResetsCache(t *testing.T) {
+	resetHelpers(t)
+	Helper()
+	const function = "github.com/acme/log.Info"
+	if isHelper(function) {
+		t.Fatalf("%s is a helper before being registered", function)
+	}
+	RegisterHelper("github.com/acme/log")
+	if !isHelper(function) {
+		t.Errorf("%s isn't a helper after registering its package", function)
+	}
+}
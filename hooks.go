@@ -2,9 +2,9 @@ package logrus
 
 // A hook to be fired when logging on the logging levels returned from
 // `Levels()` on your implementation of the interface. Note that this is not
-// fired in a goroutine or a channel with workers, you should handle such
-// functionality yourself if your call is non-blocking and you don't wish for
-// the logging calls for levels returned from `Levels()` to block.
+// fired in a goroutine or a channel with workers. If you don't wish for the
+// logging calls for levels returned from `Levels()` to block, wrap your hook
+// with the `hooks/async` package.
 type Hook interface {
 	Levels() []Level
 	Fire(*Entry) error
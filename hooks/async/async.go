@@ -0,0 +1,250 @@
+// Package async provides a hook wrapper that fires another hook from a pool
+// of background workers, so that logging calls don't block on slow hooks.
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingpp/logrus"
+)
+
+// ErrClosed is returned by Fire once the hook has been closed.
+var ErrClosed = errors.New("async hook is closed")
+
+// OverflowPolicy decides what happens to an entry fired while the queue is
+// full.
+type OverflowPolicy int
+
+const (
+	// Block waits until a worker frees a slot in the queue.
+	Block OverflowPolicy = iota
+	// DropNewest discards the entry being fired.
+	DropNewest
+	// DropOldest discards the oldest queued entry to make room.
+	DropOldest
+)
+
+const (
+	// DefaultQueueSize is the queue size of Options with no QueueSize.
+	DefaultQueueSize = 1024
+	// DefaultWorkers is the number of workers of Options with no Workers.
+	DefaultWorkers = 1
+)
+
+// Options configures a Hook. The zero value is usable: a queue of
+// DefaultQueueSize entries, DefaultWorkers workers and the Block policy.
+type Options struct {
+	// QueueSize is the number of entries that may wait for a worker.
+	QueueSize int
+
+	// Workers is the number of goroutines firing the wrapped hook. With more
+	// than one worker, entries may reach the wrapped hook out of order.
+	Workers int
+
+	// Overflow is the policy applied when the queue is full.
+	Overflow OverflowPolicy
+}
+
+// Hook fires a wrapped hook asynchronously. Each queued entry is a copy of the
+// one passed to Fire, with its fields and the maps and slices in them copied
+// too, so the caller may keep changing them. Other values in fields, such as
+// pointers or structs holding pointers, are shared with the caller and must
+// not be modified until they have been fired.
+type Hook struct {
+	// Kept first so it is 64-bit aligned for atomic access on 32-bit platforms.
+	dropped uint64
+
+	hook     logrus.Hook
+	overflow OverflowPolicy
+	queue    chan *logrus.Entry
+	workers  sync.WaitGroup
+
+	// Guards queue against being closed while Fire is sending to it.
+	closeMu sync.RWMutex
+	closed  bool
+
+	// Tracks entries which were queued but not yet fired, for Flush.
+	pendingMu sync.Mutex
+	pending   int
+	idle      chan struct{}
+}
+
+// NewHook starts the workers and returns a hook which fires hook in the
+// background. Close must be called to stop the workers.
+func NewHook(hook logrus.Hook, opts Options) *Hook {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+
+	h := &Hook{
+		hook:     hook,
+		overflow: opts.Overflow,
+		queue:    make(chan *logrus.Entry, opts.QueueSize),
+	}
+	h.workers.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go h.work()
+	}
+	return h
+}
+
+// Levels returns the levels of the wrapped hook.
+func (h *Hook) Levels() []logrus.Level {
+	return h.hook.Levels()
+}
+
+// Fire queues a copy of the entry according to the overflow policy.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	h.closeMu.RLock()
+	defer h.closeMu.RUnlock()
+	if h.closed {
+		atomic.AddUint64(&h.dropped, 1)
+		return ErrClosed
+	}
+
+	e := copyEntry(entry)
+	h.addPending()
+
+	switch h.overflow {
+	case DropNewest:
+		select {
+		case h.queue <- e:
+		default:
+			h.drop()
+		}
+	case DropOldest:
+		for {
+			select {
+			case h.queue <- e:
+				return nil
+			default:
+			}
+			select {
+			case <-h.queue:
+				h.drop()
+			default:
+			}
+		}
+	default:
+		h.queue <- e
+	}
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full
+// or the hook was closed.
+func (h *Hook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Flush waits until every entry queued so far has been fired, or ctx is done.
+func (h *Hook) Flush(ctx context.Context) error {
+	h.pendingMu.Lock()
+	if h.pending == 0 {
+		h.pendingMu.Unlock()
+		return nil
+	}
+	idle := h.idle
+	h.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting entries, fires everything still queued and waits for
+// the workers to exit. It is safe to call Close more than once.
+func (h *Hook) Close() error {
+	h.closeMu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.closeMu.Unlock()
+
+	h.workers.Wait()
+	return nil
+}
+
+func (h *Hook) work() {
+	defer h.workers.Done()
+	for e := range h.queue {
+		if err := h.hook.Fire(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+		}
+		h.donePending()
+	}
+}
+
+func (h *Hook) drop() {
+	atomic.AddUint64(&h.dropped, 1)
+	h.donePending()
+}
+
+func (h *Hook) addPending() {
+	h.pendingMu.Lock()
+	if h.pending == 0 {
+		h.idle = make(chan struct{})
+	}
+	h.pending++
+	h.pendingMu.Unlock()
+}
+
+func (h *Hook) donePending() {
+	h.pendingMu.Lock()
+	h.pending--
+	if h.pending == 0 {
+		close(h.idle)
+	}
+	h.pendingMu.Unlock()
+}
+
+// copyEntry returns a copy of entry whose Data can be read while the caller
+// keeps using the original. The Buffer only lives for the duration of the log
+// call and is never copied.
+func copyEntry(entry *logrus.Entry) *logrus.Entry {
+	e := *entry
+	e.Buffer = nil
+	e.Data = copyValue(entry.Data).(logrus.Fields)
+	return &e
+}
+
+// copyValue copies the maps and slices logrus fields are usually built of.
+// Other values are copied as is, so pointers still point to the caller's data.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case logrus.Fields:
+		m := make(logrus.Fields, len(v))
+		for k, val := range v {
+			m[k] = copyValue(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = copyValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = copyValue(val)
+		}
+		return s
+	case []byte:
+		return append([]byte(nil), v...)
+	default:
+		return v
+	}
+}
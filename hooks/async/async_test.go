@@ -0,0 +1,214 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+// recordingHook records the messages it fires. Fire blocks while gate is
+// not nil and not closed.
+type recordingHook struct {
+	gate chan struct{}
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (h *recordingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *recordingHook) Fire(e *logrus.Entry) error {
+	if h.gate != nil {
+		<-h.gate
+	}
+	h.mu.Lock()
+	h.messages = append(h.messages, e.Message)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *recordingHook) fired() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.messages...)
+}
+
+func fire(t *testing.T, h *Hook, msg string) {
+	t.Helper()
+	e := logrus.NewEntry(logrus.New())
+	e.Message = msg
+	if err := h.Fire(e); err != nil {
+		t.Fatalf("Fire(%q) = %v", msg, err)
+	}
+}
+
+// fillQueue fires "0" to be picked up by the blocked worker, then fills the
+// queue of size 2 with "1" and "2".
+func fillQueue(t *testing.T, h *Hook) {
+	t.Helper()
+	fire(t, h, "0")
+	deadline := time.Now().Add(time.Second)
+	for len(h.queue) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker didn't pick up the first entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	fire(t, h, "1")
+	fire(t, h, "2")
+}
+
+func TestDropNewest(t *testing.T) {
+	inner := &recordingHook{gate: make(chan struct{})}
+	h := NewHook(inner, Options{QueueSize: 2, Overflow: DropNewest})
+
+	fillQueue(t, h)
+	fire(t, h, "3")
+	close(inner.gate)
+	h.Close()
+
+	if got := inner.fired(); len(got) != 3 || got[2] != "2" {
+		t.Errorf("fired %v, want [0 1 2]", got)
+	}
+	if n := h.Dropped(); n != 1 {
+		t.Errorf("Dropped() = %d, want 1", n)
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	inner := &recordingHook{gate: make(chan struct{})}
+	h := NewHook(inner, Options{QueueSize: 2, Overflow: DropOldest})
+
+	fillQueue(t, h)
+	fire(t, h, "3")
+	close(inner.gate)
+	h.Close()
+
+	if got := inner.fired(); len(got) != 3 || got[1] != "2" || got[2] != "3" {
+		t.Errorf("fired %v, want [0 2 3]", got)
+	}
+	if n := h.Dropped(); n != 1 {
+		t.Errorf("Dropped() = %d, want 1", n)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	inner := &recordingHook{gate: make(chan struct{})}
+	h := NewHook(inner, Options{QueueSize: 2})
+
+	fillQueue(t, h)
+	done := make(chan struct{})
+	go func() {
+		fire(t, h, "3")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Fire returned while the queue was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(inner.gate)
+	<-done
+	h.Close()
+
+	if got := inner.fired(); len(got) != 4 {
+		t.Errorf("fired %v, want [0 1 2 3]", got)
+	}
+	if n := h.Dropped(); n != 0 {
+		t.Errorf("Dropped() = %d, want 0", n)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	inner := &recordingHook{gate: make(chan struct{})}
+	h := NewHook(inner, Options{})
+	defer h.Close()
+
+	fire(t, h, "a")
+	fire(t, h, "b")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := h.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Flush() with a blocked worker = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(inner.gate)
+	if err := h.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+	if got := inner.fired(); len(got) != 2 {
+		t.Errorf("fired %v after Flush, want [a b]", got)
+	}
+	if err := h.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() with nothing queued = %v", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	inner := &recordingHook{}
+	h := NewHook(inner, Options{Workers: 3})
+
+	for i := 0; i < 100; i++ {
+		fire(t, h, "queued")
+	}
+	h.Close()
+	if got := inner.fired(); len(got) != 100 {
+		t.Errorf("fired %d entries before Close returned, want 100", len(got))
+	}
+
+	e := logrus.NewEntry(logrus.New())
+	if err := h.Fire(e); err != ErrClosed {
+		t.Errorf("Fire() after Close = %v, want ErrClosed", err)
+	}
+	if n := h.Dropped(); n != 1 {
+		t.Errorf("Dropped() = %d, want 1", n)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
+
+func TestConcurrentFire(t *testing.T) {
+	inner := &recordingHook{}
+	h := NewHook(inner, Options{QueueSize: 16, Workers: 4, Overflow: DropOldest})
+
+	logger := logrus.New()
+	logger.Out = discard{}
+	logger.Hooks.Add(h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := logger.WithFields(logrus.Fields{
+				"map":   map[string]interface{}{"n": 0},
+				"slice": []interface{}{0},
+			})
+			for j := 0; j < 200; j++ {
+				entry.Data["map"].(map[string]interface{})["n"] = j
+				entry.Data["slice"].([]interface{})[0] = j
+				entry.Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+	h.Close()
+
+	if got := uint64(len(inner.fired())) + h.Dropped(); got != 1600 {
+		t.Errorf("fired and dropped %d entries, want 1600", got)
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,192 @@
+package logrus_syslog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingpp/logrus"
+)
+
+const (
+	// DefaultStructuredDataID is the SD-ID of the element holding the entry
+	// fields. It uses the IANA reserved example enterprise number.
+	DefaultStructuredDataID = "fields@32473"
+
+	rfc5424TimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+	rfc3164TimestampFormat = "Jan _2 15:04:05"
+)
+
+// RFC5424Formatter formats entries as RFC 5424 syslog messages. The entry
+// fields become the parameters of a single structured data element.
+type RFC5424Formatter struct {
+	// Facility the messages are logged with.
+	Facility Priority
+
+	// Header fields, the nil value "-" is used for those left empty.
+	Hostname string
+	AppName  string
+	ProcID   string
+	MsgID    string
+
+	// SD-ID of the structured data element holding the fields. Defaults to
+	// DefaultStructuredDataID.
+	StructuredDataID string
+}
+
+func (f *RFC5424Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "<%d>1 %s %s %s %s %s ",
+		priority(f.Facility, entry.Level),
+		entry.Time.Format(rfc5424TimestampFormat),
+		headerField(f.Hostname, 255),
+		headerField(f.AppName, 48),
+		headerField(f.ProcID, 128),
+		headerField(f.MsgID, 32))
+
+	if len(entry.Data) == 0 {
+		b.WriteByte('-')
+	} else {
+		id := f.StructuredDataID
+		if id == "" {
+			id = DefaultStructuredDataID
+		}
+		b.WriteByte('[')
+		b.WriteString(sdName(id))
+		for _, k := range sortedKeys(entry.Data) {
+			b.WriteByte(' ')
+			b.WriteString(sdName(k))
+			b.WriteString(`="`)
+			sdParamValue(b, stringify(entry.Data[k]))
+			b.WriteByte('"')
+		}
+		b.WriteByte(']')
+	}
+
+	if entry.Message != "" {
+		b.WriteByte(' ')
+		b.WriteString(entry.Message)
+	}
+	return b.Bytes(), nil
+}
+
+// RFC3164Formatter formats entries as BSD syslog messages. The entry fields
+// are appended to the message as key=value pairs. The timestamp is in local
+// time, as RFC 3164 expects, since it has no time zone.
+type RFC3164Formatter struct {
+	// Facility the messages are logged with.
+	Facility Priority
+
+	Hostname string
+
+	// Tag is the name of the program, followed by ProcID in brackets if set.
+	Tag    string
+	ProcID string
+}
+
+func (f *RFC3164Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "<%d>%s ", priority(f.Facility, entry.Level), entry.Time.Local().Format(rfc3164TimestampFormat))
+	if f.Hostname != "" {
+		b.WriteString(f.Hostname)
+		b.WriteByte(' ')
+	}
+	if f.Tag != "" {
+		b.WriteString(f.Tag)
+		if f.ProcID != "" {
+			fmt.Fprintf(b, "[%s]", f.ProcID)
+		}
+		b.WriteString(": ")
+	}
+
+	b.WriteString(entry.Message)
+	for _, k := range sortedKeys(entry.Data) {
+		v := stringify(entry.Data[k])
+		if strings.ContainsAny(v, " \"=") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(b, " %s=%s", k, v)
+	}
+	return b.Bytes(), nil
+}
+
+func sortedKeys(data logrus.Fields) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// headerField returns s restricted to printable US-ASCII and max characters,
+// or the nil value when s is empty.
+func headerField(s string, max int) string {
+	s = printASCII(s, nil)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// sdName returns s as a valid SD-NAME, which excludes '=', ' ', ']' and '"'
+// and is at most 32 characters long. The '@' of an SD-ID is kept.
+func sdName(s string) string {
+	s = printASCII(s, func(c byte) bool {
+		return c == '=' || c == ']' || c == '"'
+	})
+	if s == "" {
+		return "_"
+	}
+	if at := strings.IndexByte(s, '@'); at >= 0 {
+		if at > 32 {
+			return s[:32] + s[at:]
+		}
+		return s
+	}
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
+
+// printASCII replaces characters outside of printable US-ASCII, spaces and
+// those matched by invalid with '_'.
+func printASCII(s string, invalid func(byte) bool) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c <= ' ' || c > '~' || (invalid != nil && invalid(c)) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// sdParamValue writes s escaping '"', '\' and ']' as required by RFC 5424.
+func sdParamValue(b *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+}
@@ -0,0 +1,232 @@
+// Package logrus_syslog provides a hook sending entries to a syslog daemon over
+// a unix socket, UDP or TCP, and formatters producing RFC 5424 and RFC 3164
+// messages.
+package logrus_syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+// Priority is a combination of a syslog facility and severity, as in
+// `log/syslog`.
+type Priority int
+
+const severityMask = 0x07
+
+const (
+	LOG_EMERG Priority = iota
+	LOG_ALERT
+	LOG_CRIT
+	LOG_ERR
+	LOG_WARNING
+	LOG_NOTICE
+	LOG_INFO
+	LOG_DEBUG
+)
+
+const (
+	LOG_KERN Priority = iota << 3
+	LOG_USER
+	LOG_MAIL
+	LOG_DAEMON
+	LOG_AUTH
+	LOG_SYSLOG
+	LOG_LPR
+	LOG_NEWS
+	LOG_UUCP
+	LOG_CRON
+	LOG_AUTHPRIV
+	LOG_FTP
+	_ // unused
+	_ // unused
+	_ // unused
+	_ // unused
+	LOG_LOCAL0
+	LOG_LOCAL1
+	LOG_LOCAL2
+	LOG_LOCAL3
+	LOG_LOCAL4
+	LOG_LOCAL5
+	LOG_LOCAL6
+	LOG_LOCAL7
+)
+
+// Severity maps a logrus level to a syslog severity.
+func Severity(level logrus.Level) Priority {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return LOG_CRIT
+	case logrus.ErrorLevel:
+		return LOG_ERR
+	case logrus.WarnLevel:
+		return LOG_WARNING
+	case logrus.InfoLevel:
+		return LOG_INFO
+	default:
+		return LOG_DEBUG
+	}
+}
+
+// priority returns the PRI value of an entry logged with facility.
+func priority(facility Priority, level logrus.Level) Priority {
+	return facility&^severityMask | Severity(level)
+}
+
+// Sockets of the local syslog daemon on common systems.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogHook sends entries to a syslog daemon.
+type SyslogHook struct {
+	// Formatter formats the messages sent to the daemon. It is a
+	// RFC5424Formatter unless set otherwise.
+	Formatter logrus.Formatter
+
+	// OctetCounting frames messages sent over a stream connection by
+	// prefixing them with their length as per RFC 6587, instead of
+	// terminating them with a newline. It has no effect on datagrams.
+	// Without it, newlines and carriage returns inside a message are escaped
+	// as `\n` and `\r` so they can't start a forged message.
+	OctetCounting bool
+
+	network string
+	raddr   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogHook connects to the syslog daemon at raddr. The network is one of
+// "udp", "tcp", "unix" or "unixgram"; if both network and raddr are empty the
+// local daemon is used. Entries are logged with the given facility and with a
+// severity derived from their level. The tag is used as APP-NAME and defaults
+// to the program name.
+func NewSyslogHook(network, raddr string, facility Priority, tag string) (*SyslogHook, error) {
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+
+	hook := &SyslogHook{
+		Formatter: &RFC5424Formatter{
+			Facility: facility,
+			Hostname: hostname,
+			AppName:  tag,
+			ProcID:   fmt.Sprint(os.Getpid()),
+		},
+		network: network,
+		raddr:   raddr,
+	}
+	if err := hook.connect(); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (hook *SyslogHook) connect() error {
+	if hook.conn != nil {
+		hook.conn.Close()
+		hook.conn = nil
+	}
+
+	if hook.network != "" || hook.raddr != "" {
+		conn, err := net.Dial(hook.network, hook.raddr)
+		if err != nil {
+			return err
+		}
+		hook.conn = conn
+		return nil
+	}
+
+	for _, path := range localSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				hook.network, hook.raddr = network, path
+				hook.conn = conn
+				return nil
+			}
+		}
+	}
+	return errors.New("Unix syslog delivery error")
+}
+
+// Fire formats the entry and sends it, reconnecting once if the write fails.
+func (hook *SyslogHook) Fire(entry *logrus.Entry) error {
+	msg, err := hook.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+
+	if hook.conn != nil {
+		if err = hook.write(msg); err == nil {
+			return nil
+		}
+	}
+	if err = hook.connect(); err != nil {
+		return err
+	}
+	return hook.write(msg)
+}
+
+func (hook *SyslogHook) write(msg []byte) error {
+	switch hook.network {
+	case "udp", "udp4", "udp6", "unixgram":
+	default:
+		if hook.OctetCounting {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		} else {
+			msg = append(escapeNewlines(bytes.TrimRight(msg, "\r\n")), '\n')
+		}
+	}
+	hook.conn.SetWriteDeadline(time.Now().Add(30 * time.Second))
+	_, err := hook.conn.Write(msg)
+	return err
+}
+
+// escapeNewlines returns msg with CR and LF escaped, as they end a message
+// framed by newlines.
+func escapeNewlines(msg []byte) []byte {
+	if bytes.IndexAny(msg, "\r\n") < 0 {
+		return msg
+	}
+	escaped := make([]byte, 0, len(msg)+8)
+	for _, c := range msg {
+		switch c {
+		case '\n':
+			escaped = append(escaped, '\\', 'n')
+		case '\r':
+			escaped = append(escaped, '\\', 'r')
+		default:
+			escaped = append(escaped, c)
+		}
+	}
+	return escaped
+}
+
+// Levels returns all levels, the daemon decides what to keep.
+func (hook *SyslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Close closes the connection to the daemon.
+func (hook *SyslogHook) Close() error {
+	hook.mu.Lock()
+	defer hook.mu.Unlock()
+	if hook.conn == nil {
+		return nil
+	}
+	err := hook.conn.Close()
+	hook.conn = nil
+	return err
+}
@@ -0,0 +1,229 @@
+package logrus_syslog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+func testEntry(msg string) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2016, 11, 4, 10, 0, 0, 123456000, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = msg
+	return entry
+}
+
+func TestRFC5424Formatter(t *testing.T) {
+	f := &RFC5424Formatter{Facility: LOG_LOCAL0, Hostname: "host", AppName: "my app", ProcID: "42"}
+
+	entry := testEntry("A walrus appears")
+	entry.Data = logrus.Fields{"animal": "walrus", "odd key=": `a "quoted] \ value`}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<132>1 2016-11-04T10:00:00.123456Z host my_app 42 - [fields@32473 animal="walrus" odd_key_="a \"quoted\] \\ value"] A walrus appears`
+	if string(b) != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", b, want)
+	}
+
+	b, _ = f.Format(testEntry(""))
+	if want := "<132>1 2016-11-04T10:00:00.123456Z host my_app 42 - -"; string(b) != want {
+		t.Errorf("Format() without fields = %s, want %s", b, want)
+	}
+}
+
+func TestRFC3164Formatter(t *testing.T) {
+	f := &RFC3164Formatter{Facility: LOG_LOCAL0, Hostname: "host", Tag: "app", ProcID: "42"}
+
+	entry := testEntry("A walrus appears")
+	entry.Data = logrus.Fields{"animal": "walrus", "size": "very big", "err": errors.New("x=y")}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp := entry.Time.Local().Format(rfc3164TimestampFormat)
+	want := "<132>" + stamp + ` host app[42]: A walrus appears animal=walrus err="x=y" size="very big"`
+	if string(b) != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", b, want)
+	}
+}
+
+// listen starts a listener on network and returns its address and a channel
+// receiving everything read from the first connection, or each datagram.
+func listen(t *testing.T, network string) (string, <-chan string) {
+	t.Helper()
+	received := make(chan string, 10)
+
+	switch network {
+	case "udp", "unixgram":
+		addr := "127.0.0.1:0"
+		if network == "unixgram" {
+			addr = filepath.Join(t.TempDir(), "log.sock")
+		}
+		conn, err := net.ListenPacket(network, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		go func() {
+			buf := make([]byte, 64<<10)
+			for {
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					close(received)
+					return
+				}
+				received <- string(buf[:n])
+			}
+		}()
+		return conn.LocalAddr().String(), received
+	}
+
+	addr := "127.0.0.1:0"
+	if network == "unix" {
+		addr = filepath.Join(t.TempDir(), "log.sock")
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			b, err := r.ReadString('\n')
+			if b != "" {
+				received <- b
+			}
+			if err != nil {
+				close(received)
+				return
+			}
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func receive(t *testing.T, received <-chan string) string {
+	t.Helper()
+	select {
+	case s := <-received:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+		return ""
+	}
+}
+
+func newHook(t *testing.T, network, addr string) *SyslogHook {
+	t.Helper()
+	hook, err := NewSyslogHook(network, addr, LOG_LOCAL0, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { hook.Close() })
+	hook.Formatter = &RFC5424Formatter{Facility: LOG_LOCAL0, Hostname: "host", AppName: "app"}
+	return hook
+}
+
+func TestDatagrams(t *testing.T) {
+	for _, network := range []string{"udp", "unixgram"} {
+		t.Run(network, func(t *testing.T) {
+			addr, received := listen(t, network)
+			hook := newHook(t, network, addr)
+
+			for _, msg := range []string{"first", "multi\nline"} {
+				if err := hook.Fire(testEntry(msg)); err != nil {
+					t.Fatal(err)
+				}
+				want := "<132>1 2016-11-04T10:00:00.123456Z host app - - - " + msg
+				if got := receive(t, received); got != want {
+					t.Errorf("received %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamNewlineFraming(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			addr, received := listen(t, network)
+			hook := newHook(t, network, addr)
+
+			forged := "ok\n<128>1 2016-11-04T10:00:00Z host app - - - forged\r\n"
+			for _, msg := range []string{"first", forged} {
+				if err := hook.Fire(testEntry(msg)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			hook.Close()
+
+			header := "<132>1 2016-11-04T10:00:00.123456Z host app - - - "
+			for _, want := range []string{
+				header + "first\n",
+				header + `ok\n<128>1 2016-11-04T10:00:00Z host app - - - forged` + "\n",
+			} {
+				if got := receive(t, received); got != want {
+					t.Errorf("received %q, want %q", got, want)
+				}
+			}
+			if s, ok := <-received; ok {
+				t.Errorf("received extra message %q", s)
+			}
+		})
+	}
+}
+
+func TestStreamOctetCounting(t *testing.T) {
+	addr, received := listen(t, "tcp")
+	hook := newHook(t, "tcp", addr)
+	hook.Formatter = &RFC3164Formatter{Facility: LOG_LOCAL0, Tag: "app"}
+	hook.OctetCounting = true
+
+	for _, msg := range []string{"first", "multi\nline"} {
+		if err := hook.Fire(testEntry(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hook.Close()
+
+	var all strings.Builder
+	for s := range received {
+		all.WriteString(s)
+	}
+	r := strings.NewReader(all.String())
+	stamp := testEntry("").Time.Local().Format(rfc3164TimestampFormat)
+	for _, msg := range []string{"first", "multi\nline"} {
+		var n int
+		if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+			t.Fatalf("reading the length of %q: %v", msg, err)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			t.Fatal(err)
+		}
+		if want := "<132>" + stamp + " app: " + msg; string(frame) != want {
+			t.Errorf("frame = %q, want %q", frame, want)
+		}
+	}
+	if r.Len() != 0 {
+		t.Errorf("%d bytes left after the frames", r.Len())
+	}
+}
@@ -0,0 +1,119 @@
+// Package test is used for asserting on the entries logged through a logrus
+// Logger in unit tests.
+package test
+
+import (
+	"io/ioutil"
+	"sync"
+
+	"github.com/pingpp/logrus"
+)
+
+// Hook is a hook designed for dealing with logs in test scenarios. It records
+// a snapshot of every entry fired, for every level.
+type Hook struct {
+	// Entries is an array of all entries that have been received by this hook.
+	// For safe access, use the AllEntries() method, rather than reading this
+	// value directly.
+	Entries []*logrus.Entry
+	mu      sync.RWMutex
+}
+
+// NewGlobal installs a test hook for the global logger.
+func NewGlobal() *Hook {
+	hook := new(Hook)
+	logrus.AddHook(hook)
+
+	return hook
+}
+
+// NewLocal installs a test hook for a given local logger.
+func NewLocal(logger *logrus.Logger) *Hook {
+	hook := new(Hook)
+	logger.Hooks.Add(hook)
+
+	return hook
+}
+
+// NewNullLogger creates a discarding logger and installs the test hook.
+func NewNullLogger() (*logrus.Logger, *Hook) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	return logger, NewLocal(logger)
+}
+
+// Fire records a copy of the entry. The entry handed to hooks only lives for
+// the duration of the log call and its Data may still be touched by the
+// formatter, so both the entry and its fields are copied.
+func (t *Hook) Fire(e *logrus.Entry) error {
+	snapshot := *e
+	snapshot.Data = make(logrus.Fields, len(e.Data))
+	for k, v := range e.Data {
+		snapshot.Data[k] = v
+	}
+	snapshot.Buffer = nil
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Entries = append(t.Entries, &snapshot)
+	return nil
+}
+
+// Levels returns all levels, the test hook records everything.
+func (t *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// LastEntry returns the last entry that was logged or nil.
+func (t *Hook) LastEntry() *logrus.Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i := len(t.Entries) - 1
+	if i < 0 {
+		return nil
+	}
+	return t.Entries[i]
+}
+
+// AllEntries returns all entries that were logged.
+func (t *Hook) AllEntries() []*logrus.Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	entries := make([]*logrus.Entry, len(t.Entries))
+	copy(entries, t.Entries)
+	return entries
+}
+
+// EntriesAtLevel returns all entries that were logged at the given level.
+func (t *Hook) EntriesAtLevel(level logrus.Level) []*logrus.Entry {
+	return t.filter(func(e *logrus.Entry) bool {
+		return e.Level == level
+	})
+}
+
+// EntriesWithMessage returns all entries whose message equals msg.
+func (t *Hook) EntriesWithMessage(msg string) []*logrus.Entry {
+	return t.filter(func(e *logrus.Entry) bool {
+		return e.Message == msg
+	})
+}
+
+func (t *Hook) filter(match func(*logrus.Entry) bool) []*logrus.Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var entries []*logrus.Entry
+	for _, e := range t.Entries {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Reset removes all Entries from this test hook.
+func (t *Hook) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Entries = make([]*logrus.Entry, 0)
+}
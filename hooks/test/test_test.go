@@ -0,0 +1,112 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pingpp/logrus"
+)
+
+func TestNullLogger(t *testing.T) {
+	logger, hook := NewNullLogger()
+
+	if e := hook.LastEntry(); e != nil {
+		t.Fatalf("LastEntry() = %v before logging, want nil", e)
+	}
+
+	logger.Error("Helloerror")
+	if n := len(hook.Entries); n != 1 {
+		t.Fatalf("got %d entries, want 1", n)
+	}
+	e := hook.LastEntry()
+	if e.Level != logrus.ErrorLevel || e.Message != "Helloerror" {
+		t.Errorf("LastEntry() = %v %q, want error %q", e.Level, e.Message, "Helloerror")
+	}
+
+	hook.Reset()
+	if e := hook.LastEntry(); e != nil {
+		t.Errorf("LastEntry() = %v after Reset, want nil", e)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = discard{}
+	logger.SetLevel(logrus.DebugLevel)
+	hook := NewLocal(logger)
+
+	logger.Debug("one")
+	logger.WithField("k", "v").Info("two")
+	logger.Info("two")
+
+	if n := len(hook.AllEntries()); n != 3 {
+		t.Fatalf("got %d entries, want 3", n)
+	}
+	if n := len(hook.EntriesAtLevel(logrus.InfoLevel)); n != 2 {
+		t.Errorf("got %d info entries, want 2", n)
+	}
+	if n := len(hook.EntriesAtLevel(logrus.WarnLevel)); n != 0 {
+		t.Errorf("got %d warning entries, want 0", n)
+	}
+	entries := hook.EntriesWithMessage("two")
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries with message two, want 2", len(entries))
+	}
+	if v := entries[0].Data["k"]; v != "v" {
+		t.Errorf("Data[k] = %v, want v", v)
+	}
+}
+
+func TestGlobal(t *testing.T) {
+	out := logrus.StandardLogger().Out
+	logrus.SetOutput(discard{})
+	defer logrus.SetOutput(out)
+
+	hook := NewGlobal()
+	logrus.Warn("global")
+	if e := hook.LastEntry(); e == nil || e.Message != "global" || e.Level != logrus.WarnLevel {
+		t.Errorf("LastEntry() = %v, want the global warning", e)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	logger, hook := NewNullLogger()
+	entry := logger.WithField("k", "v")
+	entry.Info("first")
+	entry.Data["k"] = "changed"
+
+	if v := hook.LastEntry().Data["k"]; v != "v" {
+		t.Errorf("recorded Data[k] = %v, want the value at the time of logging", v)
+	}
+	if b := hook.LastEntry().Buffer; b != nil {
+		t.Errorf("recorded Buffer = %v, want nil", b)
+	}
+}
+
+func TestConcurrentFire(t *testing.T) {
+	logger, hook := NewNullLogger()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.WithField("j", j).Info("concurrent")
+				hook.AllEntries()
+				hook.LastEntry()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(hook.EntriesWithMessage("concurrent")); n != 1000 {
+		t.Errorf("got %d entries, want 1000", n)
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
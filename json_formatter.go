@@ -8,6 +8,14 @@ import (
 type JSONFormatter struct {
 	// TimestampFormat sets the format used for marshaling timestamps.
 	TimestampFormat string
+
+	// FieldMap allows users to customize the names of keys for default fields,
+	// e.g. `FieldMap{FieldKeyTime: "@timestamp", FieldKeyMsg: "msg"}`.
+	FieldMap FieldMap
+
+	// Write the caller as a single `caller` field, see `Entry.Caller`,
+	// instead of separate `filename`, `line` and `func` fields.
+	JoinCaller bool
 }
 
 func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
@@ -22,18 +30,34 @@ func (f *JSONFormatter) Format(entry *Entry) ([]byte, error) {
 			data[k] = v
 		}
 	}
-	prefixFieldClashes(data)
+	keys := callerKeys(entry, f.JoinCaller)
+	if len(entry.Stack) > 0 {
+		keys = append(keys, FieldKeyStack)
+	}
+	prefixFieldClashes(data, f.FieldMap, keys...)
 
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
 		timestampFormat = DefaultTimestampFormat
 	}
 
-	data["time"] = entry.Time.Format(timestampFormat)
-	data["message"] = entry.Message
-	data["level"] = entry.Level.String()
-	data["filename"] = entry.FileName
-	data["line"] = entry.Line
+	data[f.FieldMap.resolve(FieldKeyTime)] = entry.Time.Format(timestampFormat)
+	data[f.FieldMap.resolve(FieldKeyMsg)] = entry.Message
+	data[f.FieldMap.resolve(FieldKeyLevel)] = entry.Level.String()
+	if entry.FileName != "" {
+		if f.JoinCaller {
+			data[f.FieldMap.resolve(FieldKeyCaller)] = entry.Caller()
+		} else {
+			data[f.FieldMap.resolve(FieldKeyFileName)] = entry.FileName
+			data[f.FieldMap.resolve(FieldKeyLine)] = entry.Line
+			if entry.Func != "" {
+				data[f.FieldMap.resolve(FieldKeyFunc)] = entry.Func
+			}
+		}
+	}
+	if len(entry.Stack) > 0 {
+		data[f.FieldMap.resolve(FieldKeyStack)] = entry.Stack
+	}
 
 	serialized, err := json.Marshal(data)
 	if err != nil {
@@ -1,9 +1,11 @@
 package logrus
 
 import (
+	"context"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 )
 
 type Logger struct {
@@ -24,14 +26,60 @@ type Logger struct {
 	Formatter Formatter
 	// The logging level the logger should log at. This is typically (and defaults
 	// to) `logrus.Info`, which allows Info(), Warn(), Error() and Fatal() to be
-	// logged. `logrus.Debug` is useful in
+	// logged. `logrus.Debug` is useful in development.
+	// Set it when creating the logger. Once the logger is in use, change it with
+	// `SetLevel`, which is safe to call concurrently with logging, and read it
+	// with `GetLevel`. The field isn't updated by `SetLevel`.
 	Level Level
+	// How the caller of the logging method is reported in `Entry.FileName`:
+	// its base name by default, a path relative to the module root, the full
+	// path, or not at all with `CallerOff`, which also saves the cost of
+	// looking it up.
+	CallerMode CallerMode
+	// Also report the function name of the caller in `Entry.Func`.
+	CallerFunc bool
+	// Levels for which the stack trace of the goroutine is captured into
+	// `Entry.Stack`, e.g. `[]Level{ErrorLevel, FatalLevel, PanicLevel}`. If
+	// the entry's error carries a stack trace of its own through a
+	// `StackTrace()` method, that one is used instead.
+	StackTraceLevels []Level
+	// Context extractors for the logger instance. Each one is called with the
+	// context attached through `WithContext` when an entry is logged, and the
+	// fields it returns are added to the entry.
+	// Set it when creating the logger. Once the logger is in use, add
+	// extractors with `AddContextExtractor`, which is safe to call
+	// concurrently with logging.
+	ContextExtractors []ContextExtractor
+	// Removes secrets and personal data from the message and the fields of
+	// entries before they reach the hooks and the formatter, e.g.
+	// `NewRedactor()`. Nil by default.
+	Redactor *Redactor
+	// Level set by SetLevel plus one, accessed atomically. Zero until
+	// SetLevel is called, the Level field applies then, or the level of the
+	// root for a named logger without a level of its own.
+	atomicLevel uint32
+	// Per-file level rules, see SetVModule.
+	vmodule atomic.Value
+	// Context extractors added by AddContextExtractor, a []ContextExtractor
+	// replaced on every addition so it can be read without locking.
+	extractors   atomic.Value
+	extractorsMu sync.Mutex
+	// Name of a logger returned by Named, and the logger it was derived from.
+	// Only set for named loggers.
+	name string
+	root *Logger
+	// Named loggers and their configured levels. Only used on a root logger.
+	names loggerNames
 	// Used to sync writing to the log. Locking is enabled by Default
 	mu MutexWrap
 	// Reusable empty entry
 	entryPool sync.Pool
 }
 
+// A ContextExtractor turns values stored in a context, such as request IDs or
+// deadlines, into fields of the entry being logged.
+type ContextExtractor func(ctx context.Context) Fields
+
 type MutexWrap struct {
 	lock     sync.Mutex
 	disabled bool
@@ -111,226 +159,376 @@ func (logger *Logger) WithError(err error) *Entry {
 	return entry.WithError(err)
 }
 
-//logger Print family
-func (logger *Logger) Debug(args ...interface{}) {
-	if logger.Level >= DebugLevel {
+// Add a context to the log entry. The context is available to hooks and
+// formatters as `entry.Context`, and is passed to the logger's context
+// extractors when the entry is logged.
+func (logger *Logger) WithContext(ctx context.Context) *Entry {
+	entry := logger.newEntry()
+	defer logger.releaseEntry(entry)
+	return entry.WithContext(ctx)
+}
+
+// Add a context extractor to the logger. Extractors run in the order they
+// were added, after those of `ContextExtractors`. It's safe to call
+// concurrently with logging.
+func (logger *Logger) AddContextExtractor(extractor ContextExtractor) {
+	logger = logger.base()
+	logger.extractorsMu.Lock()
+	defer logger.extractorsMu.Unlock()
+	old, _ := logger.extractors.Load().([]ContextExtractor)
+	extractors := make([]ContextExtractor, len(old), len(old)+1)
+	copy(extractors, old)
+	logger.extractors.Store(append(extractors, extractor))
+}
+
+func (logger *Logger) addedExtractors() []ContextExtractor {
+	extractors, _ := logger.extractors.Load().([]ContextExtractor)
+	return extractors
+}
+
+// Log logs a message at the given level, for callers which compute the level
+// dynamically. Entries at FatalLevel then exit the program and entries at
+// PanicLevel panic, as Fatal and Panic do.
+func (logger *Logger) Log(level Level, args ...interface{}) {
+	logger.LogEx(1, level, args...)
+}
+
+func (logger *Logger) LogEx(depth int, level Level, args ...interface{}) {
+	if logger.mayLog(level) {
 		entry := logger.newEntry()
-		entry.DebugEx(1, args...)
+		entry.LogEx(1+depth, level, args...)
 		logger.releaseEntry(entry)
 	}
+	if level == FatalLevel {
+		Exit(1)
+	}
 }
 
-func (logger *Logger) Info(args ...interface{}) {
-	if logger.Level >= InfoLevel {
+func (logger *Logger) Logf(level Level, format string, args ...interface{}) {
+	logger.LogExf(1, level, format, args...)
+}
+
+func (logger *Logger) LogExf(depth int, level Level, format string, args ...interface{}) {
+	if logger.mayLog(level) {
 		entry := logger.newEntry()
-		entry.InfoEx(1, args...)
+		entry.LogExf(1+depth, level, format, args...)
 		logger.releaseEntry(entry)
 	}
+	if level == FatalLevel {
+		Exit(1)
+	}
 }
 
-func (logger *Logger) Warn(args ...interface{}) {
-	if logger.Level >= WarnLevel {
+func (logger *Logger) Logln(level Level, args ...interface{}) {
+	logger.LogExln(1, level, args...)
+}
+
+func (logger *Logger) LogExln(depth int, level Level, args ...interface{}) {
+	if logger.mayLog(level) {
 		entry := logger.newEntry()
-		entry.WarnEx(1, args...)
+		entry.LogExln(1+depth, level, args...)
 		logger.releaseEntry(entry)
 	}
+	if level == FatalLevel {
+		Exit(1)
+	}
+}
+
+//logger Print family
+func (logger *Logger) Trace(args ...interface{}) {
+	logger.LogEx(1, TraceLevel, args...)
+}
+
+func (logger *Logger) Debug(args ...interface{}) {
+	logger.LogEx(1, DebugLevel, args...)
+}
+
+func (logger *Logger) Info(args ...interface{}) {
+	logger.LogEx(1, InfoLevel, args...)
+}
+
+func (logger *Logger) Print(args ...interface{}) {
+	logger.LogEx(1, InfoLevel, args...)
+}
+
+func (logger *Logger) Warn(args ...interface{}) {
+	logger.LogEx(1, WarnLevel, args...)
+}
+
+func (logger *Logger) Warning(args ...interface{}) {
+	logger.LogEx(1, WarnLevel, args...)
 }
 
 func (logger *Logger) Error(args ...interface{}) {
-	if logger.Level >= ErrorLevel {
-		entry := logger.newEntry()
-		entry.ErrorEx(1, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(1, ErrorLevel, args...)
 }
 
 func (logger *Logger) Fatal(args ...interface{}) {
-	if logger.Level >= FatalLevel {
-		entry := logger.newEntry()
-		entry.FatalEx(1, args...)
-		logger.releaseEntry(entry)
-	}
-	Exit(1)
+	logger.LogEx(1, FatalLevel, args...)
 }
 
 func (logger *Logger) Panic(args ...interface{}) {
-	if logger.Level >= PanicLevel {
-		entry := logger.newEntry()
-		entry.PanicEx(1, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(1, PanicLevel, args...)
 }
 
 //logger PrintEx family
+func (logger *Logger) TraceEx(depth int, args ...interface{}) {
+	logger.LogEx(depth+1, TraceLevel, args...)
+}
+
 func (logger *Logger) DebugEx(depth int, args ...interface{}) {
-	if logger.Level >= DebugLevel {
-		entry := logger.newEntry()
-		entry.DebugEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(depth+1, DebugLevel, args...)
 }
 
 func (logger *Logger) InfoEx(depth int, args ...interface{}) {
-	if logger.Level >= InfoLevel {
-		entry := logger.newEntry()
-		entry.InfoEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(depth+1, InfoLevel, args...)
+}
+
+func (logger *Logger) PrintEx(depth int, args ...interface{}) {
+	logger.LogEx(depth+1, InfoLevel, args...)
 }
 
 func (logger *Logger) WarnEx(depth int, args ...interface{}) {
-	if logger.Level >= WarnLevel {
-		entry := logger.newEntry()
-		entry.WarnEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(depth+1, WarnLevel, args...)
+}
+
+func (logger *Logger) WarningEx(depth int, args ...interface{}) {
+	logger.LogEx(depth+1, WarnLevel, args...)
 }
 
 func (logger *Logger) ErrorEx(depth int, args ...interface{}) {
-	if logger.Level >= ErrorLevel {
-		entry := logger.newEntry()
-		entry.ErrorEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(depth+1, ErrorLevel, args...)
 }
 
 func (logger *Logger) FatalEx(depth int, args ...interface{}) {
-	if logger.Level >= FatalLevel {
-		entry := logger.newEntry()
-		entry.FatalEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
-	Exit(1)
+	logger.LogEx(depth+1, FatalLevel, args...)
 }
 
 func (logger *Logger) PanicEx(depth int, args ...interface{}) {
-	if logger.Level >= PanicLevel {
-		entry := logger.newEntry()
-		entry.PanicEx(1+depth, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogEx(depth+1, PanicLevel, args...)
 }
 
 // logger Printf family functions
+func (logger *Logger) Tracef(format string, args ...interface{}) {
+	logger.LogExf(1, TraceLevel, format, args...)
+}
+
 func (logger *Logger) Debugf(format string, args ...interface{}) {
-	if logger.Level >= DebugLevel {
-		entry := logger.newEntry()
-		entry.DebugExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, DebugLevel, format, args...)
 }
 
 func (logger *Logger) Infof(format string, args ...interface{}) {
-	if logger.Level >= InfoLevel {
-		entry := logger.newEntry()
-		entry.InfoExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, InfoLevel, format, args...)
+}
+
+func (logger *Logger) Printf(format string, args ...interface{}) {
+	logger.LogExf(1, InfoLevel, format, args...)
 }
 
 func (logger *Logger) Warnf(format string, args ...interface{}) {
-	if logger.Level >= WarnLevel {
-		entry := logger.newEntry()
-		entry.WarnExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, WarnLevel, format, args...)
 }
 
 func (logger *Logger) Warningf(format string, args ...interface{}) {
-	if logger.Level >= WarnLevel {
-		entry := logger.newEntry()
-		entry.WarnExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, WarnLevel, format, args...)
 }
 
 func (logger *Logger) Errorf(format string, args ...interface{}) {
-	if logger.Level >= ErrorLevel {
-		entry := logger.newEntry()
-		entry.ErrorExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, ErrorLevel, format, args...)
 }
 
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
-	if logger.Level >= FatalLevel {
-		entry := logger.newEntry()
-		entry.FatalExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
-	Exit(1)
+	logger.LogExf(1, FatalLevel, format, args...)
 }
 
 func (logger *Logger) Panicf(format string, args ...interface{}) {
-	if logger.Level >= PanicLevel {
-		entry := logger.newEntry()
-		entry.PanicExf(1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(1, PanicLevel, format, args...)
 }
 
 //logger PrintExf family
 
+func (logger *Logger) TraceExf(depth int, format string, args ...interface{}) {
+	logger.LogExf(depth+1, TraceLevel, format, args...)
+}
+
 func (logger *Logger) DebugExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= DebugLevel {
-		entry := logger.newEntry()
-		entry.DebugExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(depth+1, DebugLevel, format, args...)
 }
 
 func (logger *Logger) InfoExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= InfoLevel {
-		entry := logger.newEntry()
-		entry.InfoExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(depth+1, InfoLevel, format, args...)
+}
+
+func (logger *Logger) PrintExf(depth int, format string, args ...interface{}) {
+	logger.LogExf(depth+1, InfoLevel, format, args...)
 }
 
 func (logger *Logger) WarnExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= WarnLevel {
-		entry := logger.newEntry()
-		entry.WarnExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(depth+1, WarnLevel, format, args...)
 }
 
 func (logger *Logger) WarningExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= WarnLevel {
-		entry := logger.newEntry()
-		entry.WarnExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(depth+1, WarnLevel, format, args...)
 }
 
 func (logger *Logger) ErrorExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= ErrorLevel {
-		entry := logger.newEntry()
-		entry.ErrorExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
+	logger.LogExf(depth+1, ErrorLevel, format, args...)
 }
 
 func (logger *Logger) FatalExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= FatalLevel {
-		entry := logger.newEntry()
-		entry.FatalExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
-	}
-	Exit(1)
+	logger.LogExf(depth+1, FatalLevel, format, args...)
 }
 
 func (logger *Logger) PanicExf(depth int, format string, args ...interface{}) {
-	if logger.Level >= PanicLevel {
-		entry := logger.newEntry()
-		entry.PanicExf(depth+1, format, args...)
-		logger.releaseEntry(entry)
+	logger.LogExf(depth+1, PanicLevel, format, args...)
+}
+
+//logger Println family
+
+func (logger *Logger) Traceln(args ...interface{}) {
+	logger.LogExln(1, TraceLevel, args...)
+}
+
+func (logger *Logger) Debugln(args ...interface{}) {
+	logger.LogExln(1, DebugLevel, args...)
+}
+
+func (logger *Logger) Infoln(args ...interface{}) {
+	logger.LogExln(1, InfoLevel, args...)
+}
+
+func (logger *Logger) Println(args ...interface{}) {
+	logger.LogExln(1, InfoLevel, args...)
+}
+
+func (logger *Logger) Warnln(args ...interface{}) {
+	logger.LogExln(1, WarnLevel, args...)
+}
+
+func (logger *Logger) Warningln(args ...interface{}) {
+	logger.LogExln(1, WarnLevel, args...)
+}
+
+func (logger *Logger) Errorln(args ...interface{}) {
+	logger.LogExln(1, ErrorLevel, args...)
+}
+
+func (logger *Logger) Fatalln(args ...interface{}) {
+	logger.LogExln(1, FatalLevel, args...)
+}
+
+func (logger *Logger) Panicln(args ...interface{}) {
+	logger.LogExln(1, PanicLevel, args...)
+}
+
+//logger PrintExln family
+
+func (logger *Logger) TraceExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, TraceLevel, args...)
+}
+
+func (logger *Logger) DebugExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, DebugLevel, args...)
+}
+
+func (logger *Logger) InfoExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, InfoLevel, args...)
+}
+
+func (logger *Logger) PrintExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, InfoLevel, args...)
+}
+
+func (logger *Logger) WarnExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, WarnLevel, args...)
+}
+
+func (logger *Logger) WarningExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, WarnLevel, args...)
+}
+
+func (logger *Logger) ErrorExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, ErrorLevel, args...)
+}
+
+func (logger *Logger) FatalExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, FatalLevel, args...)
+}
+
+func (logger *Logger) PanicExln(depth int, args ...interface{}) {
+	logger.LogExln(depth+1, PanicLevel, args...)
+}
+
+func (logger *Logger) level() Level {
+	if level := atomic.LoadUint32(&logger.atomicLevel); level != 0 {
+		return Level(level - 1)
+	}
+	if logger.root != nil {
+		return logger.root.level()
+	}
+	return logger.Level
+}
+
+// SetLevel sets the logger level. It's safe to call while other goroutines
+// are logging. On a named logger it is the same as calling `SetLevelFor`
+// with its name, the level then also applies to its descendants.
+func (logger *Logger) SetLevel(level Level) {
+	if logger.root != nil {
+		logger.root.SetLevelFor(logger.name, level)
+		return
+	}
+	logger.storeLevel(level)
+}
+
+func (logger *Logger) storeLevel(level Level) {
+	atomic.StoreUint32(&logger.atomicLevel, uint32(level)+1)
+}
+
+// GetLevel returns the logger level.
+func (logger *Logger) GetLevel() Level {
+	return logger.level()
+}
+
+// IsLevelEnabled checks if the log level of the logger is greater than the
+// level param. It ignores the per-file rules of SetVModule, see
+// IsCallerLevelEnabled.
+func (logger *Logger) IsLevelEnabled(level Level) bool {
+	return logger.level() >= level
+}
+
+// IsCallerLevelEnabled checks if entries at the level param logged from the
+// calling function are logged, applying the per-file rules of SetVModule.
+// It looks up the caller when rules are set, which makes it more expensive
+// than IsLevelEnabled.
+func (logger *Logger) IsCallerLevelEnabled(level Level) bool {
+	return logger.isCallerLevelEnabled(1, level)
+}
+
+func (logger *Logger) isCallerLevelEnabled(depth int, level Level) bool {
+	if !logger.mayLog(level) {
+		return false
+	}
+	if logger.getVModule() == nil {
+		return true
+	}
+	pc, file, _, _ := callerFrame(1 + depth)
+	return logger.callerEnabled(level, pc, file)
+}
+
+// mayLog checks if entries at level are logged from some caller, by the
+// logger level or by a per-file rule. Entry.log then applies the rule of the
+// actual caller.
+func (logger *Logger) mayLog(level Level) bool {
+	if logger.level() >= level {
+		return true
 	}
+	vm := logger.getVModule()
+	return vm != nil && vm.max >= level
 }
 
 //When file is opened with appending mode, it's safe to
 //write concurrently to a file (within 4k message on Linux).
 //In these cases user can choose to disable the lock.
 func (logger *Logger) SetNoLock() {
-	logger.mu.Disable()
+	logger.base().mu.Disable()
 }
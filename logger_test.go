@@ -0,0 +1,50 @@
+package logrus
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+)
+
+type ctxKey string
+
+func TestAddContextExtractorConcurrent(t *testing.T) {
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.ContextExtractors = []ContextExtractor{func(ctx context.Context) Fields {
+		return Fields{"first": ctx.Value(ctxKey("id"))}
+	}}
+	ctx := context.WithValue(context.Background(), ctxKey("id"), "42")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.WithContext(ctx).Info("concurrent")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				logger.AddContextExtractor(func(context.Context) Fields { return nil })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(logger.addedExtractors()); n != 40 {
+		t.Errorf("got %d added extractors, want 40", n)
+	}
+	logger.AddContextExtractor(func(ctx context.Context) Fields {
+		return Fields{"first": "overridden", "second": ctx.Value(ctxKey("id"))}
+	})
+
+	entry := logger.WithContext(ctx).WithField("explicit", true)
+	entry.extractContext()
+	if entry.Data["first"] != "overridden" || entry.Data["second"] != "42" || entry.Data["explicit"] != true {
+		t.Errorf("extracted %v", entry.Data)
+	}
+}
@@ -0,0 +1,352 @@
+// Package loggrpc provides gRPC client and server interceptors logging the
+// calls through logrus.
+//
+// It's the only package of logrus depending on google.golang.org/grpc and
+// google.golang.org/protobuf, which programs not importing it don't need.
+package loggrpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pingpp/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Keys of the fields of the entries of calls.
+const (
+	FieldKeyService          = "grpc.service"
+	FieldKeyMethod           = "grpc.method"
+	FieldKeyCode             = "grpc.code"
+	FieldKeyDuration         = "grpc.duration"
+	FieldKeyRecvMsgs         = "grpc.recv_msgs"
+	FieldKeySentMsgs         = "grpc.sent_msgs"
+	FieldKeyPayload          = "grpc.payload"
+	FieldKeyPayloadTruncated = "grpc.payload_truncated"
+	FieldKeyPeer             = "peer.address"
+)
+
+// DefaultMaxPayloadSize is the size payloads are truncated to by default.
+const DefaultMaxPayloadSize = 1024
+
+// Options are the options of the interceptors.
+type Options struct {
+	// Level returns the level of the entry of a call from its code. Defaults
+	// to CodeLevel.
+	Level func(code codes.Code) logrus.Level
+
+	// Log the messages sent and received at DebugLevel, with the payload
+	// rendered as JSON for protobuf messages.
+	Payloads bool
+
+	// Size in bytes payloads are truncated to. Defaults to
+	// DefaultMaxPayloadSize, negative for no limit.
+	MaxPayloadSize int
+}
+
+// CodeLevel returns InfoLevel for codes caused by the client or expected in
+// normal operation, WarnLevel for codes of transient or operational failures
+// and ErrorLevel for codes of bugs and server failures.
+func CodeLevel(code codes.Code) logrus.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return logrus.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+type entryKey struct{}
+
+// EntryFromContext returns the entry of the call set by the interceptors, or
+// an entry of the standard logger if there is none.
+func EntryFromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.WithContext(ctx)
+}
+
+// UnaryServerInterceptor returns an interceptor logging unary calls to logger.
+// Handlers get the entry of the call through EntryFromContext. opts may be
+// nil.
+func UnaryServerInterceptor(logger logrus.FieldLogger, opts *Options) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		c := o.newCall(ctx, logger, info.FullMethod)
+		c.setPeer(peer.FromContext(c.ctx))
+
+		c.received(req)
+		resp, err := handler(c.ctx, req)
+		if err == nil {
+			c.sent(resp)
+		}
+		c.finish(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging streaming calls to
+// logger. Handlers get the entry of the call through EntryFromContext on the
+// context of the stream. opts may be nil.
+func StreamServerInterceptor(logger logrus.FieldLogger, opts *Options) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c := o.newCall(ss.Context(), logger, info.FullMethod)
+		c.setPeer(peer.FromContext(c.ctx))
+
+		err := handler(srv, &serverStream{ServerStream: ss, call: c})
+		c.finish(err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor logging unary calls to
+// logger. opts may be nil.
+func UnaryClientInterceptor(logger logrus.FieldLogger, opts *Options) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		c := o.newCall(ctx, logger, method)
+		p := &peer.Peer{}
+
+		c.sent(req)
+		err := invoker(c.ctx, method, req, reply, cc, append(callOpts, grpc.Peer(p))...)
+		if err == nil {
+			c.received(reply)
+		}
+		c.setPeer(p, true)
+		c.finish(err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor logging streaming calls to
+// logger. A call is logged once the stream ends, when RecvMsg returns an
+// error or, for calls without server streaming, the response, or when the
+// context of the call is done. opts may be nil.
+func StreamClientInterceptor(logger logrus.FieldLogger, opts *Options) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		c := o.newCall(ctx, logger, method)
+		p := &peer.Peer{}
+
+		cs, err := streamer(c.ctx, desc, cc, method, append(callOpts, grpc.Peer(p))...)
+		if err != nil {
+			c.setPeer(p, true)
+			c.finish(err)
+			return nil, err
+		}
+		// A stream abandoned by canceling its context is never read to its
+		// end.
+		go func() {
+			select {
+			case <-c.ctx.Done():
+				c.finish(status.FromContextError(c.ctx.Err()).Err())
+			case <-c.done:
+			}
+		}()
+		return &clientStream{ClientStream: cs, call: c, peer: p, serverStreams: desc.ServerStreams}, nil
+	}
+}
+
+func newOptions(opts *Options) Options {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Level == nil {
+		o.Level = CodeLevel
+	}
+	if o.MaxPayloadSize == 0 {
+		o.MaxPayloadSize = DefaultMaxPayloadSize
+	}
+	return o
+}
+
+// call is the state of a call being logged.
+type call struct {
+	opts  Options
+	ctx   context.Context
+	entry *logrus.Entry
+	start time.Time
+
+	mu       sync.Mutex
+	recvMsgs int
+	sentMsgs int
+	peer     string
+	once     sync.Once
+	done     chan struct{} // closed once the call is logged
+}
+
+func (o Options) newCall(ctx context.Context, logger logrus.FieldLogger, fullMethod string) *call {
+	service, method := splitMethod(fullMethod)
+	entry := logger.WithFields(logrus.Fields{
+		FieldKeyService: service,
+		FieldKeyMethod:  method,
+	})
+	ctx = context.WithValue(ctx, entryKey{}, entry)
+	entry.Context = ctx
+	return &call{opts: o, ctx: ctx, entry: entry, start: time.Now(), done: make(chan struct{})}
+}
+
+// splitMethod returns the service and method of a full method name such as
+// "/pkg.Service/Method".
+func splitMethod(fullMethod string) (service, method string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+func (c *call) setPeer(p *peer.Peer, ok bool) {
+	if ok && p != nil && p.Addr != nil {
+		c.mu.Lock()
+		c.peer = p.Addr.String()
+		c.mu.Unlock()
+	}
+}
+
+func (c *call) received(m interface{}) {
+	c.mu.Lock()
+	c.recvMsgs++
+	c.mu.Unlock()
+	c.logPayload("message received", m)
+}
+
+func (c *call) sent(m interface{}) {
+	c.mu.Lock()
+	c.sentMsgs++
+	c.mu.Unlock()
+	c.logPayload("message sent", m)
+}
+
+func (c *call) logPayload(msg string, m interface{}) {
+	if !c.opts.Payloads || !c.entry.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+
+	var payload string
+	if pm, ok := m.(proto.Message); ok {
+		b, err := protojson.Marshal(pm)
+		if err != nil {
+			payload = fmt.Sprintf("%v", m)
+		} else {
+			payload = string(b)
+		}
+	} else {
+		payload = fmt.Sprintf("%+v", m)
+	}
+
+	fields := logrus.Fields{FieldKeyPayload: payload}
+	if max := c.opts.MaxPayloadSize; max >= 0 && len(payload) > max {
+		// Don't cut a multi-byte character in half.
+		for max > 0 && !utf8.RuneStart(payload[max]) {
+			max--
+		}
+		fields[FieldKeyPayload] = payload[:max]
+		fields[FieldKeyPayloadTruncated] = true
+	}
+	c.entry.WithFields(fields).Debug(msg)
+}
+
+// finish logs the call with the code of err, once.
+func (c *call) finish(err error) {
+	c.once.Do(func() {
+		code := status.Code(err)
+
+		c.mu.Lock()
+		fields := logrus.Fields{
+			FieldKeyCode:     code.String(),
+			FieldKeyDuration: time.Since(c.start),
+			FieldKeyRecvMsgs: c.recvMsgs,
+			FieldKeySentMsgs: c.sentMsgs,
+		}
+		if c.peer != "" {
+			fields[FieldKeyPeer] = c.peer
+		}
+		c.mu.Unlock()
+
+		entry := c.entry.WithFields(fields)
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+		entry.Log(c.opts.Level(code), "finished call")
+		close(c.done)
+	})
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	call *call
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.call.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.call.sent(m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.call.received(m)
+	}
+	return err
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	call          *call
+	peer          *peer.Peer
+	serverStreams bool
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.call.sent(m)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case err == nil:
+		s.call.received(m)
+		if !s.serverStreams {
+			s.finish(nil)
+		}
+	case err == io.EOF:
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) finish(err error) {
+	s.call.setPeer(s.peer, true)
+	s.call.finish(err)
+}
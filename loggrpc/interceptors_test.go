@@ -0,0 +1,241 @@
+package loggrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pingpp/logrus"
+	"github.com/pingpp/logrus/hooks/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	hpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const healthService = "grpc.health.v1.Health"
+
+// setup starts a health server logging to server and returns a client of it
+// logging to client.
+func setup(t *testing.T, server, client *logrus.Logger, opts *Options, interceptors ...grpc.UnaryServerInterceptor) hpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{UnaryServerInterceptor(server, opts)}, interceptors...)...),
+		grpc.StreamInterceptor(StreamServerInterceptor(server, opts)),
+	)
+	hpb.RegisterHealthServer(s, health.NewServer())
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(client, opts)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(client, opts)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return hpb.NewHealthClient(cc)
+}
+
+// finished waits for the entry of a finished call logged to hook.
+func finished(t *testing.T, hook *test.Hook) *logrus.Entry {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, e := range hook.AllEntries() {
+			if e.Message == "finished call" {
+				return e
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no finished call logged")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func checkCall(t *testing.T, side string, e *logrus.Entry, method string, code codes.Code, level logrus.Level, recv, sent int) {
+	t.Helper()
+	if e.Data[FieldKeyService] != healthService || e.Data[FieldKeyMethod] != method {
+		t.Errorf("%s: method = %v/%v, want %s/%s", side, e.Data[FieldKeyService], e.Data[FieldKeyMethod], healthService, method)
+	}
+	if e.Data[FieldKeyCode] != code.String() || e.Level != level {
+		t.Errorf("%s: code = %v at %v, want %v at %v", side, e.Data[FieldKeyCode], e.Level, code, level)
+	}
+	if e.Data[FieldKeyRecvMsgs] != recv || e.Data[FieldKeySentMsgs] != sent {
+		t.Errorf("%s: received %v and sent %v messages, want %d and %d", side, e.Data[FieldKeyRecvMsgs], e.Data[FieldKeySentMsgs], recv, sent)
+	}
+	if _, ok := e.Data[FieldKeyPeer]; !ok {
+		t.Errorf("%s: no peer", side)
+	}
+}
+
+func TestUnary(t *testing.T) {
+	server, serverHook := test.NewNullLogger()
+	client, clientHook := test.NewNullLogger()
+	c := setup(t, server, client, nil)
+
+	if _, err := c.Check(context.Background(), &hpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	checkCall(t, "server", finished(t, serverHook), "Check", codes.OK, logrus.InfoLevel, 1, 1)
+	checkCall(t, "client", finished(t, clientHook), "Check", codes.OK, logrus.InfoLevel, 1, 1)
+}
+
+func TestUnaryLevel(t *testing.T) {
+	server, serverHook := test.NewNullLogger()
+	client, clientHook := test.NewNullLogger()
+	opts := &Options{Level: func(code codes.Code) logrus.Level {
+		if code == codes.NotFound {
+			return logrus.WarnLevel
+		}
+		return CodeLevel(code)
+	}}
+	c := setup(t, server, client, opts)
+
+	if _, err := c.Check(context.Background(), &hpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Fatal("checking an unknown service succeeded")
+	}
+	e := finished(t, serverHook)
+	checkCall(t, "server", e, "Check", codes.NotFound, logrus.WarnLevel, 1, 0)
+	if e.Data[logrus.ErrorKey] == nil {
+		t.Error("server: no error")
+	}
+	checkCall(t, "client", finished(t, clientHook), "Check", codes.NotFound, logrus.WarnLevel, 0, 1)
+}
+
+func TestStream(t *testing.T) {
+	server, serverHook := test.NewNullLogger()
+	client, clientHook := test.NewNullLogger()
+	c := setup(t, server, client, nil)
+
+	// The stream is abandoned by canceling its context after the first
+	// message, without reading its end.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := c.Watch(ctx, &hpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	e := finished(t, clientHook)
+	if e.Data[FieldKeyCode] != codes.Canceled.String() || e.Data[FieldKeyRecvMsgs] != 1 || e.Data[FieldKeySentMsgs] != 1 {
+		t.Errorf("client: code %v, received %v and sent %v messages", e.Data[FieldKeyCode], e.Data[FieldKeyRecvMsgs], e.Data[FieldKeySentMsgs])
+	}
+	checkCall(t, "server", finished(t, serverHook), "Watch", codes.Canceled, logrus.InfoLevel, 1, 1)
+}
+
+func TestPayloads(t *testing.T) {
+	server, serverHook := test.NewNullLogger()
+	server.SetLevel(logrus.DebugLevel)
+	client, _ := test.NewNullLogger()
+	c := setup(t, server, client, &Options{Payloads: true, MaxPayloadSize: 10})
+
+	if _, err := c.Check(context.Background(), &hpb.HealthCheckRequest{Service: ""}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Check(context.Background(), &hpb.HealthCheckRequest{Service: "a rather long name"}); err == nil {
+		t.Fatal("checking an unknown service succeeded")
+	}
+
+	var payloads, truncated int
+	for _, e := range serverHook.AllEntries() {
+		if e.Message != "message received" {
+			continue
+		}
+		payloads++
+		if e.Level != logrus.DebugLevel {
+			t.Errorf("payload logged at %v", e.Level)
+		}
+		payload, _ := e.Data[FieldKeyPayload].(string)
+		if len(payload) > 10 {
+			t.Errorf("payload %q longer than MaxPayloadSize", payload)
+		}
+		if e.Data[FieldKeyPayloadTruncated] == true {
+			truncated++
+		}
+	}
+	if payloads != 2 || truncated != 1 {
+		t.Errorf("logged %d payloads, %d truncated, want 2 and 1", payloads, truncated)
+	}
+}
+
+func TestPayloadTruncationUTF8(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	c := Options{Payloads: true, MaxPayloadSize: 3}.newCall(context.Background(), logger, "/pkg.Service/Method")
+
+	c.received("ééé")
+	e := hook.LastEntry()
+	if e == nil || e.Data[FieldKeyPayload] != "é" || e.Data[FieldKeyPayloadTruncated] != true {
+		t.Errorf("logged %v, want the payload cut before the second character", e)
+	}
+}
+
+func TestEntryFromContext(t *testing.T) {
+	server, _ := test.NewNullLogger()
+	client, _ := test.NewNullLogger()
+	entries := make(chan *logrus.Entry, 1)
+	probe := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		entries <- EntryFromContext(ctx)
+		return handler(ctx, req)
+	}
+	c := setup(t, server, client, nil, probe)
+
+	if _, err := c.Check(context.Background(), &hpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if handlerEntry := <-entries; handlerEntry == nil || handlerEntry.Logger != server ||
+		handlerEntry.Data[FieldKeyService] != healthService || handlerEntry.Data[FieldKeyMethod] != "Check" {
+		t.Errorf("EntryFromContext() in the handler = %v", handlerEntry.Data)
+	}
+
+	if e := EntryFromContext(context.Background()); e.Logger != logrus.StandardLogger() {
+		t.Error("EntryFromContext() without a call isn't an entry of the standard logger")
+	}
+}
+
+func TestCodeLevel(t *testing.T) {
+	for code, want := range map[codes.Code]logrus.Level{
+		codes.OK:               logrus.InfoLevel,
+		codes.Canceled:         logrus.InfoLevel,
+		codes.NotFound:         logrus.InfoLevel,
+		codes.DeadlineExceeded: logrus.WarnLevel,
+		codes.Unavailable:      logrus.WarnLevel,
+		codes.Unknown:          logrus.ErrorLevel,
+		codes.Internal:         logrus.ErrorLevel,
+		codes.Unimplemented:    logrus.ErrorLevel,
+	} {
+		if got := CodeLevel(code); got != want {
+			t.Errorf("CodeLevel(%v) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	for _, tt := range []struct {
+		fullMethod, service, method string
+	}{
+		{"/pkg.Service/Method", "pkg.Service", "Method"},
+		{"pkg.Service/Method", "pkg.Service", "Method"},
+		{"/a.b.Service/Method", "a.b.Service", "Method"},
+		{"/Method", "", "Method"},
+	} {
+		service, method := splitMethod(tt.fullMethod)
+		if service != tt.service || method != tt.method {
+			t.Errorf("splitMethod(%q) = %q, %q, want %q, %q", tt.fullMethod, service, method, tt.service, tt.method)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package loghttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+// Keys of the fields of access log entries.
+const (
+	FieldKeyMethod     = "method"
+	FieldKeyPath       = "path"
+	FieldKeyQuery      = "query"
+	FieldKeyProto      = "proto"
+	FieldKeyStatus     = "status"
+	FieldKeyBytes      = "bytes"
+	FieldKeyDuration   = "duration"
+	FieldKeyRemoteAddr = "remote_addr"
+	FieldKeyUser       = "user"
+	FieldKeyUserAgent  = "user_agent"
+	FieldKeyReferer    = "referer"
+	FieldKeyRequestID  = "request_id"
+)
+
+// DefaultRequestIDHeader is the header of request IDs by default.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// Request IDs longer than this are replaced with a generated one.
+const maxRequestIDLength = 128
+
+// validRequestID returns whether id, taken from a request, is safe to log
+// and to send back: at most maxRequestIDLength letters, digits, '.', '_' or
+// '-'.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog is a middleware logging one entry per request, with its method,
+// path, status, response size, duration, remote address, user agent and
+// request ID.
+//
+// The handler gets a request scoped entry of Logger with the method, path
+// and request ID through EntryFromContext. For Apache Combined Log Format
+// output, set AccessLogger to a logger with a CombinedLogFormatter, so the
+// entries of the handler keep the formatter of Logger.
+type AccessLog struct {
+	Logger  *logrus.Logger
+	Handler http.Handler
+
+	// Logger of the entries of the requests. Defaults to Logger.
+	AccessLogger *logrus.Logger
+
+	// Header the request ID is taken from. Requests without one, or with one
+	// that isn't made of letters, digits, '.', '_' and '-' only, get a random
+	// ID. The ID is set in the same header of the response. Defaults to
+	// DefaultRequestIDHeader.
+	RequestIDHeader string
+
+	// Level returns the level of the entry of a request from its status.
+	// Defaults to StatusLevel.
+	Level func(status int) logrus.Level
+
+	// Message of the entries. Defaults to "request".
+	Message string
+}
+
+// NewAccessLog returns a middleware logging the requests of handler.
+func NewAccessLog(logger *logrus.Logger, handler http.Handler) *AccessLog {
+	return &AccessLog{Logger: logger, Handler: handler}
+}
+
+// StatusLevel returns ErrorLevel for server errors, WarnLevel for client
+// errors and InfoLevel otherwise.
+func StatusLevel(status int) logrus.Level {
+	switch {
+	case status >= 500:
+		return logrus.ErrorLevel
+	case status >= 400:
+		return logrus.WarnLevel
+	default:
+		return logrus.InfoLevel
+	}
+}
+
+type entryKey struct{}
+
+// EntryFromContext returns the request scoped entry set by AccessLog, or an
+// entry of the standard logger if there is none.
+func EntryFromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.WithContext(ctx)
+}
+
+func (a *AccessLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	header := a.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	id := r.Header.Get(header)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	w.Header().Set(header, id)
+
+	entry := a.Logger.WithFields(logrus.Fields{
+		FieldKeyRequestID: id,
+		FieldKeyMethod:    r.Method,
+		FieldKeyPath:      r.URL.Path,
+	})
+	ctx := context.WithValue(r.Context(), entryKey{}, entry)
+	entry.Context = ctx
+
+	rw := &responseWriter{ResponseWriter: w}
+	a.Handler.ServeHTTP(rw, r.WithContext(ctx))
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	fields := logrus.Fields{
+		FieldKeyRequestID:  id,
+		FieldKeyMethod:     r.Method,
+		FieldKeyPath:       r.URL.Path,
+		FieldKeyProto:      r.Proto,
+		FieldKeyStatus:     rw.status,
+		FieldKeyBytes:      rw.bytes,
+		FieldKeyDuration:   time.Since(start),
+		FieldKeyRemoteAddr: r.RemoteAddr,
+		FieldKeyUserAgent:  r.UserAgent(),
+	}
+	if r.URL.RawQuery != "" {
+		fields[FieldKeyQuery] = r.URL.RawQuery
+	}
+	if referer := r.Referer(); referer != "" {
+		fields[FieldKeyReferer] = referer
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		fields[FieldKeyUser] = user
+	}
+
+	levelOf := a.Level
+	if levelOf == nil {
+		levelOf = StatusLevel
+	}
+	msg := a.Message
+	if msg == "" {
+		msg = "request"
+	}
+	accessLogger := a.AccessLogger
+	if accessLogger == nil {
+		accessLogger = a.Logger
+	}
+	access := accessLogger.WithFields(fields)
+	access.Context = ctx
+	access.Log(levelOf(rw.status), msg)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("loghttp: response writer doesn't support hijacking")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,109 @@
+package loghttp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pingpp/logrus"
+)
+
+func newLogger(buf *bytes.Buffer, formatter logrus.Formatter) *logrus.Logger {
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = formatter
+	logger.CallerMode = logrus.CallerOff
+	return logger
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	var out, accessOut bytes.Buffer
+	logger := newLogger(&out, &logrus.TextFormatter{DisableTimestamp: true, DisableColors: true})
+
+	h := NewAccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		EntryFromContext(r.Context()).Info("loading user")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	}))
+	h.AccessLogger = newLogger(&accessOut, &CombinedLogFormatter{})
+
+	r := httptest.NewRequest("GET", "/users/1?full=1", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("User-Agent", "test")
+	r.Header.Set(DefaultRequestIDHeader, "abc-123")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if s := out.String(); strings.Count(s, "\n") != 1 ||
+		!strings.HasPrefix(s, `level=info message="loading user" method=GET`) || !strings.Contains(s, "request_id=abc-123") {
+		t.Errorf("handler logged %q", s)
+	}
+	combined := regexp.MustCompile(`^127\.0\.0\.1 - - \[[^]]+\] "GET /users/1\?full=1 HTTP/1\.1" 404 9 "-" "test"` + "\n$")
+	if s := accessOut.String(); !combined.MatchString(s) {
+		t.Errorf("access log = %q", s)
+	}
+}
+
+func TestAccessLogDefaultLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := newLogger(&out, &logrus.JSONFormatter{})
+
+	h := NewAccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		EntryFromContext(r.Context()).Info("handled")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %q, want two entries", out.String())
+	}
+	if !strings.Contains(lines[0], `"message":"handled"`) ||
+		!strings.Contains(lines[1], `"message":"request"`) || !strings.Contains(lines[1], `"status":200`) {
+		t.Errorf("logged %q", out.String())
+	}
+}
+
+func TestAccessLogRequestID(t *testing.T) {
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	for _, tt := range []struct {
+		id   string
+		keep bool
+	}{
+		{"", false},
+		{"abc-123", true},
+		{"Req_1.2-x", true},
+		{strings.Repeat("a", maxRequestIDLength), true},
+		{strings.Repeat("a", maxRequestIDLength+1), false},
+		{"a b", false},
+		{"a=b", false},
+		{`a"b`, false},
+		{"a\tb", false},
+		{"é", false},
+	} {
+		var out bytes.Buffer
+		logger := newLogger(&out, &logrus.JSONFormatter{})
+		var got string
+		h := NewAccessLog(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = EntryFromContext(r.Context()).Data[FieldKeyRequestID].(string)
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		if tt.id != "" {
+			r.Header.Set(DefaultRequestIDHeader, tt.id)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if tt.keep && got != tt.id {
+			t.Errorf("request ID %q replaced with %q", tt.id, got)
+		}
+		if !tt.keep && !generated.MatchString(got) {
+			t.Errorf("request ID %q kept as %q, want a generated one", tt.id, got)
+		}
+		if header := w.Header().Get(DefaultRequestIDHeader); header != got {
+			t.Errorf("response header = %q, want %q", header, got)
+		}
+	}
+}
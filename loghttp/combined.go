@@ -0,0 +1,101 @@
+package loghttp
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+// CombinedTimeFormat is the time format of the Apache Combined Log Format.
+const CombinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CombinedLogFormatter formats the entries of AccessLog in the Apache
+// Combined Log Format:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326 "http://example.com/" "Mozilla/5.0"
+//
+// Missing fields are written as `-`, and quoted values are escaped like Go
+// string literals so they can't break the line.
+type CombinedLogFormatter struct{}
+
+func (f *CombinedLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+
+	host := stringField(entry, FieldKeyRemoteAddr)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	writeField(b, host)
+	b.WriteString(" - ")
+	writeField(b, stringField(entry, FieldKeyUser))
+
+	// The time the request was received, as Apache does.
+	t := entry.Time
+	if d, ok := entry.Data[FieldKeyDuration].(time.Duration); ok {
+		t = t.Add(-d)
+	}
+	b.WriteString(" [")
+	b.WriteString(t.Format(CombinedTimeFormat))
+	b.WriteString("] ")
+
+	request := stringField(entry, FieldKeyMethod) + " " + stringField(entry, FieldKeyPath)
+	if query := stringField(entry, FieldKeyQuery); query != "" {
+		request += "?" + query
+	}
+	if proto := stringField(entry, FieldKeyProto); proto != "" {
+		request += " " + proto
+	}
+	b.WriteString(strconv.Quote(request))
+
+	b.WriteByte(' ')
+	if status, ok := entry.Data[FieldKeyStatus].(int); ok {
+		b.WriteString(strconv.Itoa(status))
+	} else {
+		b.WriteByte('-')
+	}
+	b.WriteByte(' ')
+	if n, ok := entry.Data[FieldKeyBytes].(int64); ok && n > 0 {
+		b.WriteString(strconv.FormatInt(n, 10))
+	} else {
+		b.WriteByte('-')
+	}
+
+	b.WriteByte(' ')
+	writeQuoted(b, stringField(entry, FieldKeyReferer))
+	b.WriteByte(' ')
+	writeQuoted(b, stringField(entry, FieldKeyUserAgent))
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func stringField(entry *logrus.Entry, key string) string {
+	s, _ := entry.Data[key].(string)
+	return s
+}
+
+func writeField(b *bytes.Buffer, s string) {
+	if s == "" {
+		b.WriteByte('-')
+		return
+	}
+	// Unquoted fields must not contain spaces or control characters either.
+	if q := strconv.Quote(s); len(q) != len(s)+2 || strings.IndexByte(s, ' ') >= 0 {
+		b.WriteString(q)
+		return
+	}
+	b.WriteString(s)
+}
+
+func writeQuoted(b *bytes.Buffer, s string) {
+	if s == "" {
+		s = "-"
+	}
+	b.WriteString(strconv.Quote(s))
+}
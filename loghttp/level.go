@@ -0,0 +1,188 @@
+// Package loghttp provides net/http integrations for logrus.
+package loghttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+// LevelHandler reports and changes the level of a Logger at runtime.
+//
+// GET returns the current level:
+//
+//	{"level":"info"}
+//
+// PUT and POST set it, from a JSON body such as `{"level":"debug","ttl":"10m"}`
+// or from the `level` and `ttl` form values. A body without Content-Type is
+// read as JSON if it starts with `{`. With a TTL the previous level is
+// restored once it expires, and the response tells when:
+//
+//	{"level":"debug","revert_to":"info","revert_at":"2016-11-04T10:10:00Z"}
+type LevelHandler struct {
+	Logger *logrus.Logger
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	revertTo logrus.Level
+	revertAt time.Time
+}
+
+// NewLevelHandler returns a handler for the level of logger.
+func NewLevelHandler(logger *logrus.Logger) *LevelHandler {
+	return &LevelHandler{Logger: logger}
+}
+
+type levelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+type levelResponse struct {
+	Level    string     `json:"level,omitempty"`
+	RevertTo string     `json:"revert_to,omitempty"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		req, status, err := decodeLevelRequest(r)
+		if err != nil {
+			writeJSON(w, status, levelResponse{Error: err.Error()})
+			return
+		}
+		level, err := logrus.ParseLevel(req.Level)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, levelResponse{Error: err.Error()})
+			return
+		}
+		var ttl time.Duration
+		if req.TTL != "" {
+			ttl, err = time.ParseDuration(req.TTL)
+			if err != nil || ttl <= 0 {
+				writeJSON(w, http.StatusBadRequest, levelResponse{Error: "invalid ttl: " + req.TTL})
+				return
+			}
+		}
+		h.SetLevel(level, ttl)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, levelResponse{Error: "method not allowed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.state())
+}
+
+// SetLevel sets the level of the logger. With a positive ttl, the level in
+// effect before the first temporary change is restored after ttl. Setting a
+// level without ttl cancels a pending restore.
+func (h *LevelHandler) SetLevel(level logrus.Level, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	} else if ttl > 0 {
+		h.revertTo = h.Logger.GetLevel()
+	}
+
+	h.Logger.SetLevel(level)
+	if ttl <= 0 {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// A later call replaced this timer but could not stop it in time.
+		if h.timer != timer {
+			return
+		}
+		h.Logger.SetLevel(h.revertTo)
+		h.timer = nil
+	})
+	h.timer = timer
+	h.revertAt = time.Now().Add(ttl).UTC()
+}
+
+func (h *LevelHandler) state() levelResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	resp := levelResponse{Level: h.Logger.GetLevel().String()}
+	if h.timer != nil {
+		revertAt := h.revertAt
+		resp.RevertTo = h.revertTo.String()
+		resp.RevertAt = &revertAt
+	}
+	return resp
+}
+
+// decodeLevelRequest returns the request in the body or form of r, or an
+// error and the status to respond with.
+func decodeLevelRequest(r *http.Request) (levelRequest, int, error) {
+	var req levelRequest
+	var body io.Reader = r.Body
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "":
+		// Some clients omit the type of a JSON body.
+		br := bufio.NewReader(r.Body)
+		if startsWithBrace(br) {
+			body = br
+			ct = "application/json"
+		}
+	case "application/json", "application/x-www-form-urlencoded", "multipart/form-data":
+	default:
+		return req, http.StatusUnsupportedMediaType, errors.New("unsupported content type: " + ct)
+	}
+
+	if ct == "application/json" {
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return req, http.StatusBadRequest, errors.New("invalid JSON body: " + err.Error())
+		}
+	} else {
+		req.Level = r.FormValue("level")
+		req.TTL = r.FormValue("ttl")
+	}
+	if req.Level == "" {
+		return req, http.StatusBadRequest, errors.New("missing level")
+	}
+	return req, http.StatusOK, nil
+}
+
+// startsWithBrace returns whether the first byte of br other than white space
+// is '{', which is left unread.
+func startsWithBrace(br *bufio.Reader) bool {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		br.UnreadByte()
+		return c == '{'
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,79 @@
+package loghttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pingpp/logrus"
+)
+
+func serveLevel(h http.Handler, method, target, contentType, body string) (*httptest.ResponseRecorder, levelResponse) {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	var resp levelResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestLevelHandler(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		status      int
+		level       logrus.Level
+	}{
+		{"json", "/", "application/json", `{"level":"debug"}`, http.StatusOK, logrus.DebugLevel},
+		{"json without type", "/", "", "\n {\"level\":\"warning\"}", http.StatusOK, logrus.WarnLevel},
+		{"form", "/", "application/x-www-form-urlencoded", "level=error", http.StatusOK, logrus.ErrorLevel},
+		{"query", "/?level=trace", "", "", http.StatusOK, logrus.TraceLevel},
+		{"invalid json", "/", "application/json", `{"level":`, http.StatusBadRequest, logrus.InfoLevel},
+		{"missing level", "/", "", "level=debug", http.StatusBadRequest, logrus.InfoLevel},
+		{"invalid level", "/", "application/json", `{"level":"loud"}`, http.StatusBadRequest, logrus.InfoLevel},
+		{"unsupported type", "/", "text/plain", "debug", http.StatusUnsupportedMediaType, logrus.InfoLevel},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			w, resp := serveLevel(NewLevelHandler(logger), http.MethodPut, tt.target, tt.contentType, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK && resp.Error == "" {
+				t.Errorf("no error in %s", w.Body)
+			}
+			if level := logger.GetLevel(); level != tt.level {
+				t.Errorf("level %v, want %v", level, tt.level)
+			}
+		})
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	logger := logrus.New()
+	h := NewLevelHandler(logger)
+
+	w, resp := serveLevel(h, http.MethodPost, "/", "application/json", `{"level":"debug","ttl":"50ms"}`)
+	if w.Code != http.StatusOK || resp.Level != "debug" || resp.RevertTo != "info" || resp.RevertAt == nil {
+		t.Fatalf("response %d %s", w.Code, w.Body)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logger.GetLevel() != logrus.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatal("level wasn't restored")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, resp := serveLevel(h, http.MethodGet, "/", "", ""); resp.Level != "info" || resp.RevertAt != nil {
+		t.Errorf("state after the TTL = %+v", resp)
+	}
+}
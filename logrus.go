@@ -1,6 +1,7 @@
 package logrus
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -15,6 +16,8 @@ type Level uint8
 // Convert the Level to a string. E.g. PanicLevel becomes "panic".
 func (level Level) String() string {
 	switch level {
+	case TraceLevel:
+		return "trace"
 	case DebugLevel:
 		return "debug"
 	case InfoLevel:
@@ -47,6 +50,8 @@ func ParseLevel(lvl string) (Level, error) {
 		return InfoLevel, nil
 	case "debug":
 		return DebugLevel, nil
+	case "trace":
+		return TraceLevel, nil
 	}
 
 	var l Level
@@ -61,6 +66,7 @@ var AllLevels = []Level{
 	WarnLevel,
 	InfoLevel,
 	DebugLevel,
+	TraceLevel,
 }
 
 // These are the different logging levels. You can set the logging level to log
@@ -82,6 +88,8 @@ const (
 	InfoLevel
 	// DebugLevel level. Usually only enabled when debugging. Very verbose logging.
 	DebugLevel
+	// TraceLevel level. Designates finer-grained informational events than the Debug.
+	TraceLevel
 )
 
 // Won't compile if StdLogger can't be realized by a log.Logger
@@ -89,23 +97,26 @@ var (
 	_ StdLogger = &log.Logger{}
 	_ StdLogger = &Entry{}
 	_ StdLogger = &Logger{}
+
+	_ FieldLogger = &Entry{}
+	_ FieldLogger = &Logger{}
 )
 
 // StdLogger is what your logrus-enabled library should take, that way
 // it'll accept a stdlib logger and a logrus logger. There's no standard
 // interface, this is the closest we get, unfortunately.
 type StdLogger interface {
-	// Print(...interface{})
-	// Printf(string, ...interface{})
-	// Println(...interface{})
+	Print(...interface{})
+	Printf(string, ...interface{})
+	Println(...interface{})
 
 	Fatal(...interface{})
 	Fatalf(string, ...interface{})
-	//Fatalln(...interface{})
+	Fatalln(...interface{})
 
 	Panic(...interface{})
 	Panicf(string, ...interface{})
-	//Panicln(...interface{})
+	Panicln(...interface{})
 }
 
 //The FieldLogger interface generalizes the Entry and Logger types
@@ -113,31 +124,35 @@ type FieldLogger interface {
 	WithField(key string, value interface{}) *Entry
 	WithFields(fields Fields) *Entry
 	WithError(err error) *Entry
+	WithContext(ctx context.Context) *Entry
 
+	Tracef(format string, args ...interface{})
 	Debugf(format string, args ...interface{})
 	Infof(format string, args ...interface{})
-	//Printf(format string, args ...interface{})
+	Printf(format string, args ...interface{})
 	Warnf(format string, args ...interface{})
-	//Warningf(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
 	Fatalf(format string, args ...interface{})
 	Panicf(format string, args ...interface{})
 
+	Trace(args ...interface{})
 	Debug(args ...interface{})
 	Info(args ...interface{})
-	//Print(args ...interface{})
+	Print(args ...interface{})
 	Warn(args ...interface{})
-	//Warning(args ...interface{})
+	Warning(args ...interface{})
 	Error(args ...interface{})
 	Fatal(args ...interface{})
 	Panic(args ...interface{})
 
-	// Debugln(args ...interface{})
-	// Infoln(args ...interface{})
-	// Println(args ...interface{})
-	// Warnln(args ...interface{})
-	// Warningln(args ...interface{})
-	// Errorln(args ...interface{})
-	// Fatalln(args ...interface{})
-	// Panicln(args ...interface{})
+	Traceln(args ...interface{})
+	Debugln(args ...interface{})
+	Infoln(args ...interface{})
+	Println(args ...interface{})
+	Warnln(args ...interface{})
+	Warningln(args ...interface{})
+	Errorln(args ...interface{})
+	Fatalln(args ...interface{})
+	Panicln(args ...interface{})
 }
@@ -0,0 +1,122 @@
+package logrus
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type loggerNames struct {
+	mu       sync.Mutex
+	children map[string]*Logger
+	levels   map[string]Level
+}
+
+// base returns the logger holding the output configuration: the root logger
+// for a named logger, the logger itself otherwise.
+func (logger *Logger) base() *Logger {
+	if logger.root != nil {
+		return logger.root
+	}
+	return logger
+}
+
+// Named returns a child logger named after this one and name, joined with a
+// dot, e.g. `logger.Named("db").Named("pool")` is named "db.pool". Entries of
+// the child carry its name in the `LoggerNameKey` field. Calling Named again
+// with the same name returns the same logger.
+//
+// A named logger writes to the `Out`, through the `Formatter` and `Hooks` of
+// the root logger it was derived from, so changes to those must be made on
+// the root. Its level is the one set for its name with `SetLevelFor`, or else
+// for its closest configured ancestor, or else the current level of the root.
+//
+// The root keeps its named loggers for as long as it lives, so names should
+// come from a fixed set such as the components of a program, not from
+// request data.
+func (logger *Logger) Named(name string) *Logger {
+	if logger.name != "" {
+		name = logger.name + "." + name
+	}
+	root := logger.base()
+
+	root.names.mu.Lock()
+	defer root.names.mu.Unlock()
+
+	if child, ok := root.names.children[name]; ok {
+		return child
+	}
+	if root.names.children == nil {
+		root.names.children = make(map[string]*Logger)
+	}
+
+	child := &Logger{
+		Out:       root.Out,
+		Hooks:     root.Hooks,
+		Formatter: root.Formatter,
+		name:      name,
+		root:      root,
+	}
+	child.storeNamedLevel(root.namedLevel(name))
+	root.names.children[name] = child
+	return child
+}
+
+// Name returns the name of a logger returned by Named, or "" for a root
+// logger.
+func (logger *Logger) Name() string {
+	return logger.name
+}
+
+// SetLevelFor sets the level of the named logger called name and of its
+// descendants which have no level of their own. An empty name sets the root
+// logger level.
+func (logger *Logger) SetLevelFor(name string, level Level) {
+	root := logger.base()
+	if name == "" {
+		root.SetLevel(level)
+		return
+	}
+
+	root.names.mu.Lock()
+	defer root.names.mu.Unlock()
+
+	if root.names.levels == nil {
+		root.names.levels = make(map[string]Level)
+	}
+	root.names.levels[name] = level
+	root.updateNamedLevels()
+}
+
+// namedLevel returns the level configured for name or its closest configured
+// ancestor, if any. It must be called with names.mu held.
+func (logger *Logger) namedLevel(name string) (Level, bool) {
+	for {
+		if level, ok := logger.names.levels[name]; ok {
+			return level, true
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			return 0, false
+		}
+		name = name[:dot]
+	}
+}
+
+// storeNamedLevel stores the configured level of a named logger, which
+// otherwise reads the level of its root.
+func (logger *Logger) storeNamedLevel(level Level, ok bool) {
+	if ok {
+		logger.storeLevel(level)
+	} else {
+		atomic.StoreUint32(&logger.atomicLevel, 0)
+	}
+}
+
+// updateNamedLevels stores the configured level of every named logger. It
+// must be called with names.mu held.
+func (logger *Logger) updateNamedLevels() {
+	for name, child := range logger.names.children {
+		child.storeNamedLevel(logger.namedLevel(name))
+	}
+}
@@ -0,0 +1,48 @@
+package logrus
+
+import "testing"
+
+func TestNamedLevels(t *testing.T) {
+	root := New()
+	db := root.Named("db")
+	pool := db.Named("pool")
+	http := root.Named("http")
+
+	if root.Named("db") != db || db.Named("pool") != pool {
+		t.Fatal("Named returned a new logger for an existing name")
+	}
+	if name := pool.Name(); name != "db.pool" {
+		t.Errorf("Name() = %q, want db.pool", name)
+	}
+
+	root.Level = WarnLevel
+	if level := pool.GetLevel(); level != WarnLevel {
+		t.Errorf("level %v after setting the root field, want warning", level)
+	}
+	root.SetLevel(ErrorLevel)
+	if level := http.GetLevel(); level != ErrorLevel {
+		t.Errorf("level %v after SetLevel on the root, want error", level)
+	}
+
+	root.SetLevelFor("db", DebugLevel)
+	if level := pool.GetLevel(); level != DebugLevel {
+		t.Errorf("pool level %v, want debug from db", level)
+	}
+	pool.SetLevel(TraceLevel)
+	if level := pool.GetLevel(); level != TraceLevel {
+		t.Errorf("pool level %v, want trace", level)
+	}
+	if level := db.GetLevel(); level != DebugLevel {
+		t.Errorf("db level %v after setting pool, want debug", level)
+	}
+
+	root.SetLevel(InfoLevel)
+	if db.GetLevel() != DebugLevel || http.GetLevel() != InfoLevel {
+		t.Errorf("levels db %v, http %v after SetLevel on the root, want debug and info", db.GetLevel(), http.GetLevel())
+	}
+
+	late := db.Named("late")
+	if level := late.GetLevel(); level != DebugLevel {
+		t.Errorf("level %v of a logger named after SetLevelFor, want debug", level)
+	}
+}
@@ -0,0 +1,448 @@
+package logrus
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Redaction is how a secret value is replaced.
+type Redaction uint8
+
+const (
+	// RedactMask replaces the value with the mask of the redactor.
+	RedactMask Redaction = iota
+	// RedactHash replaces the value with a salted hash of it, e.g.
+	// `sha256:5f2b0d3a9c1e7f64`, so equal values can still be correlated.
+	RedactHash
+	// RedactTruncate keeps the start of the value, e.g. `4111...`. Values
+	// too short to be cut are masked.
+	RedactTruncate
+	// RedactDrop removes the field. Secrets found in the message or inside a
+	// value are masked instead.
+	RedactDrop
+)
+
+// KeyRule redacts the values of fields whose key matches, ignoring case. Set
+// one of Exact, Glob or Regexp. Keys of nested maps and struct fields are
+// matched too.
+type KeyRule struct {
+	// Key equal to Exact, e.g. `password`.
+	Exact string
+	// Key matching the `path.Match` pattern Glob, e.g. `*_token`.
+	Glob string
+	// Key matching Regexp, which should be case insensitive as keys are
+	// lowered first.
+	Regexp *regexp.Regexp
+
+	Redaction Redaction
+}
+
+func (rule *KeyRule) match(key string) bool {
+	switch {
+	case rule.Exact != "":
+		return key == strings.ToLower(rule.Exact)
+	case rule.Glob != "":
+		ok, _ := path.Match(strings.ToLower(rule.Glob), key)
+		return ok
+	case rule.Regexp != nil:
+		return rule.Regexp.MatchString(key)
+	}
+	return false
+}
+
+// Detector finds secrets in strings, in the message and in the values of any
+// field.
+type Detector struct {
+	Name string
+	// Find returns the start and end indexes of the secrets in s, as
+	// `regexp.FindAllStringIndex` does.
+	Find      func(s string) [][]int
+	Redaction Redaction
+}
+
+var (
+	panRegexp    = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	jwtRegexp    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	emailRegexp  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	awsKeyRegexp = regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b|(?i:aws_?secret_?(?:access_?)?key)["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}\b`)
+)
+
+var (
+	// DetectPAN finds card numbers: 13 to 19 digits, possibly grouped with
+	// spaces or dashes, starting with the prefix of a major card network
+	// with a length it issues, and with a valid Luhn check digit. Other
+	// numbers passing the Luhn check, such as numeric IDs, are left alone.
+	DetectPAN = Detector{Name: "pan", Find: findPAN, Redaction: RedactMask}
+	// DetectJWT finds JSON Web Tokens.
+	DetectJWT = Detector{Name: "jwt", Find: findRegexp(jwtRegexp), Redaction: RedactMask}
+	// DetectEmail finds email addresses.
+	DetectEmail = Detector{Name: "email", Find: findRegexp(emailRegexp), Redaction: RedactMask}
+	// DetectAWSKey finds AWS access key IDs, and secret access keys assigned
+	// to an `aws_secret_access_key` or alike.
+	DetectAWSKey = Detector{Name: "aws_key", Find: findRegexp(awsKeyRegexp), Redaction: RedactMask}
+)
+
+func findRegexp(re *regexp.Regexp) func(string) [][]int {
+	return func(s string) [][]int {
+		return re.FindAllStringIndex(s, -1)
+	}
+}
+
+func findPAN(s string) [][]int {
+	var found [][]int
+	for _, loc := range panRegexp.FindAllStringIndex(s, -1) {
+		pan := s[loc[0]:loc[1]]
+		if luhnValid(pan) && issuedPAN(pan) {
+			found = append(found, loc)
+		}
+	}
+	return found
+}
+
+// cardRange is a range of card number prefixes, the IINs of a card network,
+// and the lengths of its numbers.
+type cardRange struct {
+	low, high      int // prefixes of the same number of digits
+	minLen, maxLen int
+}
+
+var cardRanges = []cardRange{
+	{4, 4, 13, 19},       // Visa
+	{51, 55, 16, 16},     // Mastercard
+	{2221, 2720, 16, 16}, // Mastercard
+	{34, 34, 15, 15},     // American Express
+	{37, 37, 15, 15},     // American Express
+	{6011, 6011, 16, 19}, // Discover
+	{644, 649, 16, 19},   // Discover
+	{65, 65, 16, 19},     // Discover
+	{3528, 3589, 16, 19}, // JCB
+	{300, 305, 14, 19},   // Diners Club
+	{36, 36, 14, 19},     // Diners Club
+	{38, 39, 16, 19},     // Diners Club
+	{62, 62, 16, 19},     // UnionPay
+}
+
+// issuedPAN returns whether the digits of s, ignoring anything else, start
+// with the prefix of a card network and have a length it issues.
+func issuedPAN(s string) bool {
+	digits := make([]byte, 0, 19)
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= '0' && c <= '9' {
+			digits = append(digits, c)
+		}
+	}
+	for _, cr := range cardRanges {
+		if len(digits) < cr.minLen || len(digits) > cr.maxLen {
+			continue
+		}
+		n := len(strconv.Itoa(cr.low))
+		prefix, _ := strconv.Atoi(string(digits[:n]))
+		if prefix >= cr.low && prefix <= cr.high {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnValid checks the digits of s, ignoring anything else, against the Luhn
+// check digit.
+func luhnValid(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+// DefaultMask replaces masked values by default.
+const DefaultMask = "[REDACTED]"
+
+// Redactions deeper than this in nested values are not applied, which also
+// stops at cyclic values.
+const maxRedactDepth = 10
+
+// Redactor removes secrets and personal data from entries before they are
+// passed to the hooks and the formatter. Set it as the `Redactor` of a Logger.
+type Redactor struct {
+	// Rules for the keys of fields.
+	Keys []KeyRule
+	// Detectors of secrets in the message and in string values.
+	Detectors []Detector
+	// Replaces masked values, defaults to DefaultMask.
+	Mask string
+	// Salt of the hashes of RedactHash. Set it to a secret, so short values
+	// can't be found by hashing all candidates.
+	Salt []byte
+	// Number of characters RedactTruncate keeps, defaults to 4.
+	TruncateLength int
+}
+
+// NewRedactor returns a redactor for fields named like passwords, tokens,
+// keys and authorization headers, and for card numbers, JWTs, emails and AWS
+// keys in any value.
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Keys: []KeyRule{
+			{Glob: "*password*"},
+			{Glob: "*passwd*"},
+			{Glob: "*secret*"},
+			{Glob: "*token*"},
+			{Glob: "*api_key*"},
+			{Glob: "*apikey*"},
+			{Exact: "authorization"},
+			{Exact: "cookie"},
+			{Exact: "set-cookie"},
+		},
+		Detectors: []Detector{DetectPAN, DetectJWT, DetectEmail, DetectAWSKey},
+	}
+}
+
+// Redact redacts the message and the fields of entry. The fields are replaced
+// by a copy if anything is redacted, as they may be shared with other entries.
+//
+// Values are never modified in place. A map or struct with something
+// redacted inside is replaced by Fields of its keys or exported fields, named
+// as in JSON, and a slice or array by a `[]interface{}`, so they lose their
+// type. Values with nothing redacted keep theirs.
+func (r *Redactor) Redact(entry *Entry) {
+	entry.Message = r.redactString(entry.Message)
+	if data, changed := r.redactFields(entry.Data, 0); changed {
+		entry.Data = data
+	}
+}
+
+func (r *Redactor) redactFields(fields Fields, depth int) (Fields, bool) {
+	var redacted Fields
+	for k, v := range fields {
+		nv, drop, changed := r.redactField(k, v, depth)
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(Fields, len(fields))
+			for k, v := range fields {
+				redacted[k] = v
+			}
+		}
+		if drop {
+			delete(redacted, k)
+		} else {
+			redacted[k] = nv
+		}
+	}
+	if redacted == nil {
+		return fields, false
+	}
+	return redacted, true
+}
+
+// redactField returns the redacted value of the field key, and whether the
+// field is dropped or changed at all.
+func (r *Redactor) redactField(key string, value interface{}, depth int) (interface{}, bool, bool) {
+	lower := strings.ToLower(key)
+	for i := range r.Keys {
+		if r.Keys[i].match(lower) {
+			if r.Keys[i].Redaction == RedactDrop {
+				return nil, true, true
+			}
+			return r.replace(fmt.Sprint(value), r.Keys[i].Redaction), false, true
+		}
+	}
+	v, changed := r.redactValue(value, depth)
+	return v, false, changed
+}
+
+// redactValue applies the detectors to a value and the key rules to its
+// nested maps and structs.
+func (r *Redactor) redactValue(value interface{}, depth int) (interface{}, bool) {
+	if depth >= maxRedactDepth {
+		return value, false
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		s := r.redactString(v)
+		return s, s != v
+	case Fields:
+		return r.redactFields(v, depth+1)
+	case map[string]interface{}:
+		return r.redactFields(Fields(v), depth+1)
+	case error:
+		s := v.Error()
+		if rs := r.redactString(s); rs != s {
+			return rs, true
+		}
+		return value, false
+	case fmt.Stringer:
+		// Types with a String method are usually formatted with it, except
+		// structs and maps, which the JSON formatter marshals field by field
+		// so their fields are checked first.
+		if !isStructOrMap(value) {
+			return r.redactStringer(v)
+		}
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return value, false
+		}
+		if redacted, changed := r.redactValue(rv.Elem().Interface(), depth+1); changed {
+			return redacted, true
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value, false
+		}
+		fields := make(Fields, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+		if redacted, changed := r.redactFields(fields, depth+1); changed {
+			return redacted, true
+		}
+	case reflect.Struct:
+		fields := make(Fields, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if name, ok := structFieldName(field); ok {
+				fields[name] = rv.Field(i).Interface()
+			}
+		}
+		if redacted, changed := r.redactFields(fields, depth+1); changed {
+			return redacted, true
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			// Raw bytes.
+			return value, false
+		}
+		var redacted []interface{}
+		for i := 0; i < rv.Len(); i++ {
+			nv, changed := r.redactValue(rv.Index(i).Interface(), depth+1)
+			if !changed {
+				continue
+			}
+			if redacted == nil {
+				redacted = make([]interface{}, rv.Len())
+				for j := range redacted {
+					redacted[j] = rv.Index(j).Interface()
+				}
+			}
+			redacted[i] = nv
+		}
+		if redacted != nil {
+			return redacted, true
+		}
+	}
+	if v, ok := value.(fmt.Stringer); ok {
+		// The text formatter writes the String of structs and maps.
+		return r.redactStringer(v)
+	}
+	return value, false
+}
+
+// structFieldName returns the name of a struct field in its JSON encoding,
+// or false if the field is left out of it.
+func structFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// isStructOrMap returns whether value is a struct or a map, or a pointer to
+// one.
+func isStructOrMap(value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct || rv.Kind() == reflect.Map
+}
+
+func (r *Redactor) redactStringer(v fmt.Stringer) (interface{}, bool) {
+	s := v.String()
+	if rs := r.redactString(s); rs != s {
+		return rs, true
+	}
+	return v, false
+}
+
+// redactString replaces the secrets found by the detectors in s.
+func (r *Redactor) redactString(s string) string {
+	for i := range r.Detectors {
+		d := &r.Detectors[i]
+		locs := d.Find(s)
+		if len(locs) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range locs {
+			if loc[0] < last {
+				continue
+			}
+			b.WriteString(s[last:loc[0]])
+			b.WriteString(r.replace(s[loc[0]:loc[1]], d.Redaction))
+			last = loc[1]
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+func (r *Redactor) replace(s string, redaction Redaction) string {
+	switch redaction {
+	case RedactHash:
+		mac := hmac.New(sha256.New, r.Salt)
+		mac.Write([]byte(s))
+		return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+	case RedactTruncate:
+		n := r.TruncateLength
+		if n <= 0 {
+			n = 4
+		}
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n]) + "..."
+		}
+		// Nothing would be cut off.
+		fallthrough
+	default:
+		if r.Mask != "" {
+			return r.Mask
+		}
+		return DefaultMask
+	}
+}
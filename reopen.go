@@ -0,0 +1,125 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// A Reopener is an output which can reopen its underlying file, e.g. after it
+// was moved away by `logrotate(8)`.
+type Reopener interface {
+	Reopen() error
+}
+
+// ReopenFile is an `io.WriteCloser` which appends to Filename and reopens it
+// on demand. Set it as `Logger.Out` and call `Logger.Reopen` or
+// `Logger.ReopenOnSignal` so that writes move to the new file after external
+// rotation instead of following the renamed one.
+type ReopenFile struct {
+	Filename string
+
+	mu   sync.RWMutex
+	file *os.File
+}
+
+// NewReopenFile opens filename for appending.
+func NewReopenFile(filename string) (*ReopenFile, error) {
+	f := &ReopenFile{Filename: filename}
+	if err := f.Reopen(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the currently open file.
+func (f *ReopenFile) Write(p []byte) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	return f.file.Write(p)
+}
+
+// Reopen opens Filename again and swaps it in for the current file. The new
+// file is opened before the old one is closed, so a failed reopen leaves the
+// writer usable.
+func (f *ReopenFile) Reopen() error {
+	file, err := os.OpenFile(f.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	old := f.file
+	f.file = file
+	f.mu.Unlock()
+
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (f *ReopenFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Reopen reopens `logger.Out` if it implements `Reopener`. The logger lock is
+// held meanwhile, so no entry is split across the old and the new file.
+func (logger *Logger) Reopen() error {
+	logger = logger.base()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if r, ok := logger.Out.(Reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// ReopenOnSignal calls `Reopen` every time one of the given signals is
+// received, `SIGHUP` if none is given. Errors are written to stderr. The
+// returned function stops listening for the signals.
+func (logger *Logger) ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sig...)
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				if err := logger.Reopen(); err != nil {
+					logger.base().mu.Lock()
+					fmt.Fprintf(os.Stderr, "Failed to reopen log, %v\n", err)
+					logger.base().mu.Unlock()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
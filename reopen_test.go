@@ -0,0 +1,123 @@
+// +build !windows
+
+package logrus
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newReopenLogger(t *testing.T) (*Logger, string) {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewReopenFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	logger := New()
+	logger.Out = f
+	logger.Formatter = &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	return logger, name
+}
+
+func checkLog(t *testing.T, name string, want ...string) {
+	t.Helper()
+	s := readFile(t, name)
+	if n := strings.Count(s, "\n"); n != len(want) {
+		t.Errorf("%s has %d lines, want %d: %q", name, n, len(want), s)
+	}
+	for _, w := range want {
+		if !strings.Contains(s, "message="+w+" ") {
+			t.Errorf("%s = %q, want it to contain %s", name, s, w)
+		}
+	}
+}
+
+func TestLoggerReopen(t *testing.T) {
+	logger, name := newReopenLogger(t)
+
+	logger.Info("before")
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("renamed")
+	if err := logger.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after")
+
+	checkLog(t, name+".1", "before", "renamed")
+	checkLog(t, name, "after")
+}
+
+func TestReopenFileClosed(t *testing.T) {
+	logger, _ := newReopenLogger(t)
+	f := logger.Out.(*ReopenFile)
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("x")); err != os.ErrClosed {
+		t.Errorf("Write() after Close() = %v, want os.ErrClosed", err)
+	}
+}
+
+// sighup sends SIGHUP to the process and waits until it's delivered to hup.
+func sighup(t *testing.T, hup <-chan os.Signal) {
+	t.Helper()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-hup:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP not delivered")
+	}
+}
+
+func TestLoggerReopenOnSignal(t *testing.T) {
+	// Keep SIGHUP from killing the test once ReopenOnSignal stops.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	logger, name := newReopenLogger(t)
+	stop := logger.ReopenOnSignal()
+	defer stop()
+
+	logger.Info("before")
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	sighup(t, hup)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(name); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not reopened after SIGHUP")
+		}
+	}
+	logger.Info("after")
+
+	checkLog(t, name+".1", "before")
+	checkLog(t, name, "after")
+
+	stop()
+	stop()
+	if err := os.Rename(name, name+".2"); err != nil {
+		t.Fatal(err)
+	}
+	sighup(t, hup)
+	logger.Info("stopped")
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("reopened after stop: %v", err)
+	}
+	checkLog(t, name+".2", "after", "stopped")
+}
@@ -0,0 +1,321 @@
+package logrus
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Layout of the timestamp in backup file names. It sorts lexically and
+	// contains no characters that are special on common file systems.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// RotatingFile is an `io.WriteCloser` which writes to Filename and rotates it
+// by size and/or by time. It's meant to be set as `Logger.Out`:
+//
+//	logger.Out = &logrus.RotatingFile{
+//	  Filename:   "/var/log/app/app.log",
+//	  MaxSize:    100 << 20,
+//	  Interval:   24 * time.Hour,
+//	  MaxBackups: 7,
+//	  Compress:   true,
+//	}
+//
+// A rotated file is renamed to `name-<timestamp>.ext`, e.g.
+// `app-2016-11-04T10-00-00.000.log`, with the UTC time of the rotation, and
+// gets a `.gz` suffix once compressed. If that name is taken, e.g. after two
+// rotations within a millisecond, a counter is added: `app-<timestamp>-1.log`.
+// The file is opened with `O_APPEND`, so writes smaller than 4k stay atomic as
+// described for `SetNoLock`.
+type RotatingFile struct {
+	// The file to write to. Its directory is created if it doesn't exist.
+	Filename string
+
+	// Rotate when writing would grow the file beyond MaxSize bytes. Zero
+	// disables size based rotation.
+	MaxSize int64
+
+	// Rotate at every multiple of Interval since the zero time, e.g. at
+	// midnight UTC for 24 hours. An empty file is kept rather than rotated.
+	// Zero disables time based rotation.
+	Interval time.Duration
+
+	// Maximum number of rotated files to keep. Zero keeps them all.
+	MaxBackups int
+
+	// Maximum age of rotated files, based on the timestamp in their name.
+	// Zero keeps them regardless of age.
+	MaxAge time.Duration
+
+	// Compress rotated files with gzip. Compression runs in the background.
+	Compress bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+
+	// Serializes compression and removal of old backups, which run in the
+	// background after each rotation.
+	millMu sync.Mutex
+	millWg sync.WaitGroup
+}
+
+// Write writes p to the current file, rotating it first when needed.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, moves it aside and opens a new one.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	return r.rotate()
+}
+
+// Close closes the current file and waits for background compression and
+// cleanup to finish.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+
+	r.millWg.Wait()
+	return err
+}
+
+func (r *RotatingFile) shouldRotate(n int64) bool {
+	if r.MaxSize > 0 && r.size > 0 && r.size+n > r.MaxSize {
+		return true
+	}
+	if r.Interval > 0 && !time.Now().Before(r.rotateAt) {
+		if r.size > 0 {
+			return true
+		}
+		// Nothing was written during the interval, keep the empty file.
+		r.rotateAt = r.nextRotation()
+	}
+	return false
+}
+
+func (r *RotatingFile) nextRotation() time.Time {
+	return time.Now().UTC().Truncate(r.Interval).Add(r.Interval)
+}
+
+func (r *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.Filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	if r.Interval > 0 {
+		r.rotateAt = r.nextRotation()
+	}
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	if err := os.Rename(r.Filename, r.backupName(time.Now().UTC())); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	if r.Compress || r.MaxBackups > 0 || r.MaxAge > 0 {
+		r.millWg.Add(1)
+		go r.mill()
+	}
+	return nil
+}
+
+// backupName returns an unused name for a backup rotated at t.
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(r.Filename)
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(r.Filename), ext)
+	name := filepath.Join(dir, prefix+"-"+t.Format(backupTimeFormat)+ext)
+	for i := 1; exists(name) || exists(name+compressSuffix); i++ {
+		name = filepath.Join(dir, prefix+"-"+t.Format(backupTimeFormat)+"-"+strconv.Itoa(i)+ext)
+	}
+	return name
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+	// Counter of backups rotated at the same time.
+	n int
+}
+
+// backups returns the rotated files of Filename, newest first.
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	dir := filepath.Dir(r.Filename)
+	ext := filepath.Ext(r.Filename)
+	prefix := strings.TrimSuffix(filepath.Base(r.Filename), ext) + "-"
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, compressSuffix)
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(ts, ext)
+		var n int
+		if len(ts) > len(backupTimeFormat) {
+			counter := strings.TrimPrefix(ts[len(backupTimeFormat):], "-")
+			if n, err = strconv.Atoi(counter); err != nil || n <= 0 {
+				continue
+			}
+			ts = ts[:len(backupTimeFormat)]
+		}
+		t, err := time.Parse(backupTimeFormat, ts)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t, n: n})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].n > backups[j].n
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+// mill removes the backups beyond MaxBackups or MaxAge and compresses the
+// remaining ones.
+func (r *RotatingFile) mill() {
+	defer r.millWg.Done()
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	backups, err := r.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list rotated log files, %v\n", err)
+		return
+	}
+
+	cutoff := time.Now().UTC().Add(-r.MaxAge)
+	for i, b := range backups {
+		if (r.MaxBackups > 0 && i >= r.MaxBackups) || (r.MaxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to remove rotated log file, %v\n", err)
+			}
+			continue
+		}
+		if r.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if err := compressFile(b.path); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to compress rotated log file, %v\n", err)
+			}
+		}
+	}
+}
+
+// compressFile gzips src to src.gz and removes src. The compressed file is
+// written under a temporary name first, so a partial file never looks like a
+// valid backup.
+func compressFile(src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	dst := src + compressSuffix
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if err == nil {
+		err = gz.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,196 @@
+package logrus
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func write(t *testing.T, r *RotatingFile, s string) {
+	t.Helper()
+	if _, err := r.Write([]byte(s)); err != nil {
+		t.Fatalf("Write(%q) = %v", s, err)
+	}
+}
+
+func listBackups(t *testing.T, r *RotatingFile) []backupFile {
+	t.Helper()
+	backups, err := r.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backups
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log"), MaxSize: 10}
+	defer r.Close()
+
+	write(t, r, "1234\n")
+	write(t, r, "6789\n")
+	if backups := listBackups(t, r); len(backups) != 0 {
+		t.Fatalf("rotated before reaching MaxSize: %v", backups)
+	}
+
+	write(t, r, "abc\n")
+	backups := listBackups(t, r)
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+	if s := readFile(t, backups[0].path); s != "1234\n6789\n" {
+		t.Errorf("backup = %q", s)
+	}
+	if s := readFile(t, r.Filename); s != "abc\n" {
+		t.Errorf("current file = %q", s)
+	}
+
+	// A single write larger than MaxSize goes to a fresh file but isn't split.
+	write(t, r, "a longer line\n")
+	if n := len(listBackups(t, r)); n != 2 {
+		t.Errorf("got %d backups, want 2", n)
+	}
+	if s := readFile(t, r.Filename); s != "a longer line\n" {
+		t.Errorf("current file = %q", s)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log"), Interval: time.Hour}
+	defer r.Close()
+
+	write(t, r, "first\n")
+	r.rotateAt = time.Now().Add(-time.Second)
+	write(t, r, "second\n")
+
+	backups := listBackups(t, r)
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+	if s := readFile(t, backups[0].path); s != "first\n" {
+		t.Errorf("backup = %q", s)
+	}
+	if !r.rotateAt.After(time.Now()) {
+		t.Errorf("next rotation at %v, want it in the future", r.rotateAt)
+	}
+}
+
+func TestRotatingFileIntervalEmpty(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log"), Interval: time.Hour}
+	defer r.Close()
+
+	write(t, r, "")
+	r.rotateAt = time.Now().Add(-time.Second)
+	write(t, r, "first\n")
+
+	if backups := listBackups(t, r); len(backups) != 0 {
+		t.Errorf("rotated an empty file: %v", backups)
+	}
+	if s := readFile(t, r.Filename); s != "first\n" {
+		t.Errorf("current file = %q", s)
+	}
+	if !r.rotateAt.After(time.Now()) {
+		t.Errorf("next rotation at %v, want it in the future", r.rotateAt)
+	}
+}
+
+func TestRotatingFileCollision(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log")}
+	defer r.Close()
+
+	// Rotations this close usually share a millisecond.
+	for _, s := range []string{"first\n", "second\n", "third\n"} {
+		write(t, r, s)
+		if err := r.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups := listBackups(t, r)
+	if len(backups) != 3 {
+		t.Fatalf("got %d backups, want 3", len(backups))
+	}
+	for i, want := range []string{"third\n", "second\n", "first\n"} {
+		if s := readFile(t, backups[i].path); s != want {
+			t.Errorf("backup %d (%s) = %q, want %q", i, filepath.Base(backups[i].path), s, want)
+		}
+	}
+}
+
+func TestRotatingFileBackupNames(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log")}
+	ts := time.Date(2016, 11, 4, 10, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{
+		"app-2016-11-04T10-00-00.000.log",
+		"app-2016-11-04T10-00-00.000-1.log.gz",
+		"app-2016-11-04T10-00-00.000-2.log",
+		"app-2016-11-04T10-00-00.000-x.log",
+		"app-2016-11-04T10-00-00.000-0.log",
+		"app-2016-11-04T10-00-00.000.txt",
+		"other.log",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(filepath.Dir(r.Filename), name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups := listBackups(t, r)
+	var names []string
+	for _, b := range backups {
+		names = append(names, filepath.Base(b.path))
+		if !b.time.Equal(ts) {
+			t.Errorf("%s parsed as %v, want %v", filepath.Base(b.path), b.time, ts)
+		}
+	}
+	want := []string{
+		"app-2016-11-04T10-00-00.000-2.log",
+		"app-2016-11-04T10-00-00.000-1.log.gz",
+		"app-2016-11-04T10-00-00.000.log",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("backups = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("backups = %v, want %v", names, want)
+			break
+		}
+	}
+
+	if name := filepath.Base(r.backupName(ts)); name != "app-2016-11-04T10-00-00.000-3.log" {
+		t.Errorf("backupName() = %s, want the first free counter", name)
+	}
+}
+
+func TestRotatingFileMaxBackups(t *testing.T) {
+	r := &RotatingFile{Filename: filepath.Join(t.TempDir(), "app.log"), MaxBackups: 2}
+
+	for _, s := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		write(t, r, s)
+		if err := r.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := listBackups(t, r)
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2", len(backups))
+	}
+	for i, want := range []string{"4\n", "3\n"} {
+		if s := readFile(t, backups[i].path); s != want {
+			t.Errorf("backup %d = %q, want %q", i, s, want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+//go:build go1.21
+// +build go1.21
+
+package logrus
+
+import (
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// SlogLevel returns the slog level of level. Fatal and Panic are above
+// slog.LevelError, in steps of 4 as between the slog levels.
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case PanicLevel:
+		return slog.LevelError + 8
+	case FatalLevel:
+		return slog.LevelError + 4
+	case ErrorLevel:
+		return slog.LevelError
+	case WarnLevel:
+		return slog.LevelWarn
+	case InfoLevel:
+		return slog.LevelInfo
+	case DebugLevel:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug - 4
+	}
+}
+
+// LevelFromSlog returns the level of an slog level. Levels in between are
+// rounded down, e.g. slog.LevelInfo+2 is InfoLevel, and levels above
+// slog.LevelError are ErrorLevel, so a record never exits or panics.
+func LevelFromSlog(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	case level >= slog.LevelDebug:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// SlogHandlerOptions are the options of a SlogHandler.
+type SlogHandlerOptions struct {
+	// Nest the attributes of groups in Fields, e.g. `Fields{"req":
+	// Fields{"id": 1}}`. By default they are flattened into keys joined by
+	// dots, e.g. `req.id`.
+	NestGroups bool
+}
+
+// SlogHandler is an slog.Handler logging the records through a Logger, so
+// they go through its hooks and formatter. The level of the logger decides
+// which records are enabled, and the caller is taken from the record.
+type SlogHandler struct {
+	logger *Logger
+	nest   bool
+	fields Fields
+	groups []string
+}
+
+// NewSlogHandler returns a handler logging through logger, opts may be nil.
+func NewSlogHandler(logger *Logger, opts *SlogHandlerOptions) *SlogHandler {
+	if opts == nil {
+		opts = &SlogHandlerOptions{}
+	}
+	return &SlogHandler{logger: logger, nest: opts.NestGroups, fields: Fields{}}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.mayLog(LevelFromSlog(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := LevelFromSlog(r.Level)
+	if !h.logger.mayLog(level) {
+		return nil
+	}
+
+	entry := NewEntry(h.logger)
+	copyNested(entry.Data, h.fields)
+	r.Attrs(func(a slog.Attr) bool {
+		h.addAttr(entry.Data, h.groups, a)
+		return true
+	})
+	entry.Context = ctx
+	entry.pc = r.PC
+	entry.log(0, level, r.Message)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.fields = h.cloneFields()
+	for _, a := range attrs {
+		h2.addAttr(h2.fields, h2.groups, a)
+	}
+	return &h2
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// cloneFields copies the fields of the handler, including the nested ones of
+// groups, so adding to them doesn't change the handler.
+func (h *SlogHandler) cloneFields() Fields {
+	return cloneNested(h.fields)
+}
+
+func cloneNested(fields Fields) Fields {
+	data := make(Fields, len(fields))
+	copyNested(data, fields)
+	return data
+}
+
+// copyNested copies fields into data, cloning the nested ones of groups.
+func copyNested(data, fields Fields) {
+	for k, v := range fields {
+		if group, ok := v.(Fields); ok {
+			v = cloneNested(group)
+		}
+		data[k] = v
+	}
+}
+
+func (h *SlogHandler) addAttr(fields Fields, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		// Groups without a key are inlined.
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range attrs {
+			h.addAttr(fields, groups, ga)
+		}
+		return
+	}
+
+	if !h.nest {
+		if len(groups) > 0 {
+			a.Key = strings.Join(groups, ".") + "." + a.Key
+		}
+		fields[a.Key] = a.Value.Any()
+		return
+	}
+
+	for _, group := range groups {
+		sub, ok := fields[group].(Fields)
+		if !ok {
+			sub = Fields{}
+			fields[group] = sub
+		}
+		fields = sub
+	}
+	fields[a.Key] = a.Value.Any()
+}
+
+// NewSlogLogger returns a logger logging through handler, e.g. to use code
+// written against FieldLogger with an slog backend. The entries are passed
+// to the handler as records with their fields as attributes, and the level
+// of the logger is the lowest one enabled by the handler.
+func NewSlogLogger(handler slog.Handler) *Logger {
+	logger := New()
+	logger.Out = ioutil.Discard
+	logger.Formatter = &discardFormatter{}
+	logger.Hooks.Add(&slogHook{handler: handler})
+
+	logger.SetLevel(PanicLevel)
+	for _, level := range AllLevels {
+		if handler.Enabled(context.Background(), SlogLevel(level)) && level > logger.GetLevel() {
+			logger.SetLevel(level)
+		}
+	}
+	return logger
+}
+
+// slogHook passes the entries of a logger to an slog.Handler.
+type slogHook struct {
+	handler slog.Handler
+}
+
+func (hook *slogHook) Levels() []Level {
+	return AllLevels
+}
+
+func (hook *slogHook) Fire(entry *Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := SlogLevel(entry.Level)
+	if !hook.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	// The program counter of the caller isn't kept, as it may point to an
+	// inlined logrus method, so the caller is passed as an attribute.
+	r := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	if entry.FileName != "" {
+		r.AddAttrs(slog.String(FieldKeyCaller, entry.Caller()))
+	}
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, entry.Data[k]))
+	}
+	return hook.handler.Handle(ctx, r)
+}
+
+// discardFormatter formats nothing, for loggers whose hooks do the output.
+type discardFormatter struct{}
+
+func (f *discardFormatter) Format(entry *Entry) ([]byte, error) {
+	return nil, nil
+}
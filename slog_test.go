@@ -0,0 +1,87 @@
+//go:build go1.21
+// +build go1.21
+
+package logrus
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func newSlogTest(opts *SlogHandlerOptions) (*slog.Logger, *entryHook) {
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+	hook := &entryHook{}
+	logger.Hooks.Add(hook)
+	return slog.New(NewSlogHandler(logger, opts)), hook
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	l, hook := newSlogTest(nil)
+	l.Handler().(*SlogHandler).logger.StackTraceLevels = []Level{ErrorLevel}
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Error("failed")
+	if len(hook.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(hook.entries))
+	}
+	e := hook.entries[0]
+	if e.FileName != "slog_test.go" || e.Line != line+1 {
+		t.Errorf("caller = %s:%d, want slog_test.go:%d", e.FileName, e.Line, line+1)
+	}
+	if len(e.Stack) == 0 || e.Stack[0].Line != line+1 {
+		t.Errorf("stack = %v, want it to start at line %d", e.Stack, line+1)
+	}
+	if atomic.LoadInt32(&callerHelpers.enabled) != 0 {
+		t.Error("NewSlogHandler registered a caller helper")
+	}
+}
+
+func TestSlogHandlerFields(t *testing.T) {
+	l, hook := newSlogTest(&SlogHandlerOptions{NestGroups: true})
+	l = l.With("app", "walrus").WithGroup("req").With("method", "GET")
+
+	l.Info("first", "id", 1)
+	l.Info("second", slog.Group("user", "name", "bob"))
+	if len(hook.entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(hook.entries))
+	}
+
+	first := hook.entries[0].Data
+	req, _ := first["req"].(Fields)
+	if first["app"] != "walrus" || req["method"] != "GET" || req["id"] != int64(1) {
+		t.Errorf("first entry fields = %v", first)
+	}
+	second := hook.entries[1].Data
+	req, _ = second["req"].(Fields)
+	if _, ok := req["id"]; ok {
+		t.Errorf("the attributes of a record were added to the handler: %v", second)
+	}
+	if user, _ := req["user"].(Fields); user["name"] != "bob" {
+		t.Errorf("second entry fields = %v", second)
+	}
+}
+
+func TestSlogHandlerFlatGroups(t *testing.T) {
+	l, hook := newSlogTest(nil)
+	l.WithGroup("req").Info("request", "method", "GET", slog.Group("user", "id", 7))
+	if len(hook.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(hook.entries))
+	}
+	data := hook.entries[0].Data
+	if data["req.method"] != "GET" || data["req.user.id"] != int64(7) {
+		t.Errorf("fields = %v", data)
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l, hook := newSlogTest(nil)
+	l.Debug("disabled")
+	l.Warn("enabled")
+	if len(hook.entries) != 1 || hook.entries[0].Level != WarnLevel {
+		t.Errorf("got %d entries, want the warning only", len(hook.entries))
+	}
+}
@@ -0,0 +1,127 @@
+package logrus
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// maxStackDepth is the maximum number of frames captured for an entry.
+const maxStackDepth = 64
+
+// Frame is a single frame of the stack trace of an entry.
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// captureStack returns the stack of the goroutine, skipping skip frames as
+// runtime.Callers does.
+func captureStack(skip int) []Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	return trimHelpers(framesOf(pcs[:n]))
+}
+
+// trimToPC removes the frames above the one of pc from a stack trace, e.g.
+// those of log/slog above the caller of a record. The stack is unchanged if
+// it doesn't contain pc.
+func trimToPC(stack []Frame, pc uintptr) []Frame {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	for i, f := range stack {
+		if f.Func == frame.Function && f.Line == frame.Line && f.File == frame.File {
+			return stack[i:]
+		}
+	}
+	return stack
+}
+
+func framesOf(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	stack := make([]Frame, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{Func: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+var frameType = reflect.TypeOf(Frame{})
+
+// errorStack returns the stack carried by err, or by the deepest error it
+// wraps which carries one. An error carries a stack if it has a
+// `StackTrace()` method returning a slice of program counters, such as the
+// errors of github.com/pkg/errors, or a slice of Frame.
+func errorStack(err error) []Frame {
+	var stack []Frame
+	for ; err != nil && !isNilPointer(err); err = errors.Unwrap(err) {
+		if s := stackTraceOf(err); s != nil {
+			stack = s
+		}
+	}
+	return stack
+}
+
+// isNilPointer returns whether err is a typed nil, on which its methods
+// would likely panic.
+func isNilPointer(err error) bool {
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// stackTracer is implemented by errors carrying a stack of Frame.
+type stackTracer interface {
+	StackTrace() []Frame
+}
+
+func stackTraceOf(err error) []Frame {
+	if st, ok := err.(stackTracer); ok {
+		return st.StackTrace()
+	}
+
+	// Other stack types, such as the `errors.StackTrace` of
+	// github.com/pkg/errors, can't be asserted without importing them.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Len() == 0 {
+		return nil
+	}
+
+	switch elem := out.Type().Elem(); {
+	case elem == frameType:
+		// A named slice type of Frame.
+		return out.Convert(reflect.TypeOf([]Frame(nil))).Interface().([]Frame)
+	case elem.Kind() == reflect.Uintptr:
+		pcs := make([]uintptr, out.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(out.Index(i).Uint())
+		}
+		return framesOf(pcs)
+	}
+	return nil
+}
+
+// isStackTraceLevel returns whether a stack trace is captured for entries
+// logged at level.
+func (logger *Logger) isStackTraceLevel(level Level) bool {
+	for _, l := range logger.base().StackTraceLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package logrus
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+type frameError struct{ stack []Frame }
+
+func (e *frameError) Error() string       { return "frames" }
+func (e *frameError) StackTrace() []Frame { return e.stack }
+
+// pkgFrame and pcStack mirror the stack types of github.com/pkg/errors.
+type pkgFrame uintptr
+type pcStack []pkgFrame
+
+type pcError struct{ stack pcStack }
+
+func (e *pcError) Error() string       { return "pcs" }
+func (e *pcError) StackTrace() pcStack { return e.stack }
+func (e *pcError) Unwrap() error       { return e.cause() }
+func (e *pcError) cause() *frameError  { return nil }
+
+func TestErrorStack(t *testing.T) {
+	frames := []Frame{{Func: "main.main", File: "main.go", Line: 1}}
+	if s := errorStack(fmt.Errorf("wrapped: %w", &frameError{frames})); len(s) != 1 || s[0] != frames[0] {
+		t.Errorf("stack of a wrapped frameError = %v", s)
+	}
+
+	pc, _, _, _ := runtime.Caller(0)
+	s := errorStack(&pcError{pcStack{pkgFrame(pc)}})
+	if len(s) != 1 || s[0].Func != "github.com/pingpp/logrus.TestErrorStack" {
+		t.Errorf("stack of a pcError = %v", s)
+	}
+
+	var typedNil *frameError
+	if s := errorStack(typedNil); s != nil {
+		t.Errorf("stack of a typed nil = %v", s)
+	}
+	// Unwrap returns a typed nil *frameError.
+	if s := errorStack(&pcError{}); s != nil {
+		t.Errorf("stack of an error wrapping a typed nil = %v", s)
+	}
+}
+
+func TestJSONStackClash(t *testing.T) {
+	f := &JSONFormatter{}
+	entry := NewEntry(New())
+	entry.Data["stack"] = "user value"
+
+	decode := func() map[string]interface{} {
+		b, err := f.Format(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	m := decode()
+	if m["stack"] != "user value" || m["fields.stack"] != nil {
+		t.Errorf("without a stack trace: %v", m)
+	}
+
+	entry.Stack = []Frame{{Func: "main.main", File: "main.go", Line: 1}}
+	m = decode()
+	if m["fields.stack"] != "user value" {
+		t.Errorf("with a stack trace: %v", m)
+	}
+	if _, ok := m["stack"].([]interface{}); !ok {
+		t.Errorf("stack = %v, want the frames", m["stack"])
+	}
+}
@@ -0,0 +1,102 @@
+package logrus
+
+import (
+	"io/ioutil"
+	"log"
+	"runtime"
+	"strings"
+)
+
+// stdLogWriter is the output given to a standard library `*log.Logger`. Each
+// write is one line formatted by it, which is logged as an entry after its
+// prefix, date, time and file are stripped.
+type stdLogWriter struct {
+	logger *Logger
+	level  Level
+	std    *log.Logger
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := parseStdLogLine(string(p), w.std.Prefix(), w.std.Flags())
+	w.logger.LogEx(1+stdLogDepth(), w.level, msg)
+	return len(p), nil
+}
+
+// stdLogDepth returns the number of frames of the log package above the
+// Write calling it, so the entry reports the caller of log.Printf and
+// friends.
+func stdLogDepth() int {
+	var pcs [8]uintptr
+	// Skip runtime.Callers, stdLogDepth and Write.
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	depth := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return depth
+		}
+		depth++
+		if !more {
+			return depth
+		}
+	}
+}
+
+// parseStdLogLine strips the header the standard library log package writes
+// for the given prefix and flags from line.
+func parseStdLogLine(line, prefix string, flags int) string {
+	line = strings.TrimSuffix(line, "\n")
+
+	if flags&log.Lmsgprefix == 0 {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	if flags&log.Ldate != 0 && len(line) >= len("2009/01/23 ") {
+		line = line[len("2009/01/23 "):]
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		n := len("01:23:23 ")
+		if flags&log.Lmicroseconds != 0 {
+			n += len(".123123")
+		}
+		if len(line) >= n {
+			line = line[n:]
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(line, ": "); i >= 0 {
+			line = line[i+2:]
+		}
+	}
+	if flags&log.Lmsgprefix != 0 {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	return line
+}
+
+// RedirectStdLogger makes l, a standard library logger, log through logger
+// at level. Its prefix and flags are still honoured by l and are stripped
+// from the message, and entries report the caller of l rather than the log
+// package. The returned function restores the previous output of l.
+func (logger *Logger) RedirectStdLogger(l *log.Logger, level Level) (restore func()) {
+	prev := l.Writer()
+	l.SetOutput(&stdLogWriter{logger: logger, level: level, std: l})
+	return func() {
+		l.SetOutput(prev)
+	}
+}
+
+// RedirectStdLog makes the standard library log package log through logger
+// at level, see `RedirectStdLogger`. The returned function restores its
+// previous output.
+func (logger *Logger) RedirectStdLog(level Level) (restore func()) {
+	return logger.RedirectStdLogger(log.Default(), level)
+}
+
+// StdLogger returns a standard library logger which logs through logger at
+// level, e.g. for `http.Server.ErrorLog`.
+func (logger *Logger) StdLogger(level Level) *log.Logger {
+	l := log.New(ioutil.Discard, "", 0)
+	logger.RedirectStdLogger(l, level)
+	return l
+}
@@ -0,0 +1,98 @@
+package logrus
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// entryHook records the entries it fires.
+type entryHook struct {
+	entries []*Entry
+}
+
+func (h *entryHook) Levels() []Level {
+	return AllLevels
+}
+
+func (h *entryHook) Fire(e *Entry) error {
+	h.entries = append(h.entries, e)
+	return nil
+}
+
+func TestRedirectStdLogger(t *testing.T) {
+	var out, stdOut bytes.Buffer
+	logger := New()
+	logger.Out = &out
+	hook := &entryHook{}
+	logger.Hooks.Add(hook)
+
+	l := log.New(&stdOut, "[db] ", log.LstdFlags|log.Lmicroseconds|log.Lshortfile)
+	restore := logger.RedirectStdLogger(l, WarnLevel)
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Printf("connection reset")
+	l.Println("retrying")
+
+	if len(hook.entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(hook.entries))
+	}
+	e := hook.entries[0]
+	if e.Message != "connection reset" || e.Level != WarnLevel {
+		t.Errorf("entry = %v %q, want warning %q", e.Level, e.Message, "connection reset")
+	}
+	if e.FileName != "stdlog_test.go" || e.Line != line+1 {
+		t.Errorf("caller = %s:%d, want stdlog_test.go:%d", e.FileName, e.Line, line+1)
+	}
+	if e := hook.entries[1]; e.Message != "retrying" || e.Line != line+2 {
+		t.Errorf("entry = %q at line %d, want %q at line %d", e.Message, e.Line, "retrying", line+2)
+	}
+	if stdOut.Len() != 0 {
+		t.Errorf("wrote %q to the previous output", stdOut.String())
+	}
+
+	restore()
+	l.Print("restored")
+	if len(hook.entries) != 2 || !strings.HasSuffix(stdOut.String(), "restored\n") {
+		t.Errorf("logged %d entries and wrote %q after restore", len(hook.entries), stdOut.String())
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	logger := New()
+	logger.Out = &bytes.Buffer{}
+	hook := &entryHook{}
+	logger.Hooks.Add(hook)
+
+	l := logger.StdLogger(ErrorLevel)
+	l.SetPrefix("http: ")
+	l.SetFlags(log.Lmsgprefix)
+	l.Print("TLS handshake error")
+	if len(hook.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(hook.entries))
+	}
+	if e := hook.entries[0]; e.Message != "TLS handshake error" || e.Level != ErrorLevel || e.FileName != "stdlog_test.go" {
+		t.Errorf("entry = %v %q from %s", e.Level, e.Message, e.FileName)
+	}
+}
+
+func TestParseStdLogLine(t *testing.T) {
+	for _, tt := range []struct {
+		line   string
+		prefix string
+		flags  int
+		want   string
+	}{
+		{"message\n", "", 0, "message"},
+		{"app: message\n", "app: ", 0, "message"},
+		{"app: 2009/01/23 01:23:23 message\n", "app: ", log.LstdFlags, "message"},
+		{"2009/01/23 01:23:23.123123 a.go:23: app: message\n", "app: ", log.LstdFlags | log.Lmicroseconds | log.Lshortfile | log.Lmsgprefix, "message"},
+		{"/src/a.go:23: a: b\n", "", log.Llongfile, "a: b"},
+	} {
+		if got := parseStdLogLine(tt.line, tt.prefix, tt.flags); got != tt.want {
+			t.Errorf("parseStdLogLine(%q, %q, %d) = %q, want %q", tt.line, tt.prefix, tt.flags, got, tt.want)
+		}
+	}
+}
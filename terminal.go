@@ -0,0 +1,34 @@
+package logrus
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// Colour conventions read from the environment at start up:
+//
+//   - `NO_COLOR` set to a non-empty value disables colours, see https://no-color.org.
+//   - `FORCE_COLOR` set to anything but "0" or "false" enables them even when
+//     the output is not a terminal.
+//   - `TERM=dumb` disables them.
+var (
+	envNoColor    bool
+	envForceColor bool
+)
+
+func init() {
+	envNoColor = os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+	switch strings.ToLower(os.Getenv("FORCE_COLOR")) {
+	case "", "0", "false":
+	default:
+		envForceColor = true
+	}
+}
+
+// isColorTerminal returns true if w is a terminal which supports colours.
+// Only an `*os.File` can be a terminal.
+func isColorTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminalFd(f.Fd()) && enableColors(f.Fd())
+}
@@ -6,3 +6,13 @@ package logrus
 func IsTerminal() bool {
 	return true
 }
+
+// isTerminalFd returns true if the given file descriptor is a terminal.
+func isTerminalFd(fd uintptr) bool {
+	return true
+}
+
+// enableColors returns true as terminals interpret ANSI escape sequences.
+func enableColors(fd uintptr) bool {
+	return true
+}
@@ -15,8 +15,17 @@ import (
 
 // IsTerminal returns true if stderr's file descriptor is a terminal.
 func IsTerminal() bool {
-	fd := syscall.Stderr
+	return isTerminalFd(uintptr(syscall.Stderr))
+}
+
+// isTerminalFd returns true if the given file descriptor is a terminal.
+func isTerminalFd(fd uintptr) bool {
 	var termios Termios
-	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, uintptr(fd), ioctlReadTermios, uintptr(unsafe.Pointer(&termios)), 0, 0, 0)
+	_, _, err := syscall.Syscall6(syscall.SYS_IOCTL, fd, ioctlReadTermios, uintptr(unsafe.Pointer(&termios)), 0, 0, 0)
 	return err == 0
 }
+
+// enableColors returns true as terminals interpret ANSI escape sequences.
+func enableColors(fd uintptr) bool {
+	return true
+}
@@ -8,8 +8,18 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-// IsTerminal returns true if the given file descriptor is a terminal.
+// IsTerminal returns true if stdout's file descriptor is a terminal.
 func IsTerminal() bool {
-	_, err := unix.IoctlGetTermios(int(os.Stdout.Fd()), unix.TCGETA)
+	return isTerminalFd(os.Stdout.Fd())
+}
+
+// isTerminalFd returns true if the given file descriptor is a terminal.
+func isTerminalFd(fd uintptr) bool {
+	_, err := unix.IoctlGetTermios(int(fd), unix.TCGETA)
 	return err == nil
 }
+
+// enableColors returns true as terminals interpret ANSI escape sequences.
+func enableColors(fd uintptr) bool {
+	return true
+}
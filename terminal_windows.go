@@ -16,12 +16,34 @@ var kernel32 = syscall.NewLazyDLL("kernel32.dll")
 
 var (
 	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
 )
 
+const enableVirtualTerminalProcessing = 0x0004
+
 // IsTerminal returns true if stderr's file descriptor is a terminal.
 func IsTerminal() bool {
-	fd := syscall.Stderr
+	return isTerminalFd(uintptr(syscall.Stderr))
+}
+
+// isTerminalFd returns true if the given file handle is a console.
+func isTerminalFd(fd uintptr) bool {
+	var st uint32
+	r, _, e := syscall.Syscall(procGetConsoleMode.Addr(), 2, fd, uintptr(unsafe.Pointer(&st)), 0)
+	return r != 0 && e == 0
+}
+
+// enableColors makes the console of the given file handle interpret ANSI
+// escape sequences, and returns false if it can't, as before Windows 10.
+func enableColors(fd uintptr) bool {
 	var st uint32
-	r, _, e := syscall.Syscall(procGetConsoleMode.Addr(), 2, uintptr(fd), uintptr(unsafe.Pointer(&st)), 0)
+	r, _, e := syscall.Syscall(procGetConsoleMode.Addr(), 2, fd, uintptr(unsafe.Pointer(&st)), 0)
+	if r == 0 || e != 0 {
+		return false
+	}
+	if st&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, e = syscall.Syscall(procSetConsoleMode.Addr(), 2, fd, uintptr(st|enableVirtualTerminalProcessing), 0)
 	return r != 0 && e == 0
 }
@@ -3,10 +3,13 @@ package logrus
 import (
 	"bytes"
 	"fmt"
-	"runtime"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -20,12 +23,14 @@ const (
 
 var (
 	baseTimestamp time.Time
-	isTerminal    bool
 )
 
+// maxTerminalFiles is the number of outputs a TextFormatter remembers
+// whether they are a TTY.
+const maxTerminalFiles = 8
+
 func init() {
 	baseTimestamp = time.Now()
-	isTerminal = IsTerminal()
 }
 
 func miniTS() int {
@@ -39,6 +44,12 @@ type TextFormatter struct {
 	// Force disabling colors.
 	DisableColors bool
 
+	// Without ForceColors or DisableColors, colors are used when the output of
+	// the logger is a TTY. The result of the check is kept per file, so it's
+	// done again when the output changes.
+	terminalMu    sync.Mutex
+	terminalFiles map[*os.File]bool
+
 	// Disable timestamp logging. useful when output is redirected to logging
 	// system that already adds timestamps.
 	DisableTimestamp bool
@@ -54,12 +65,41 @@ type TextFormatter struct {
 	// that log extremely frequently and don't use the JSON formatter this may not
 	// be desired.
 	DisableSorting bool
+
+	// FieldMap allows users to customize the names of keys for default fields.
+	// It applies to the output without colors.
+	FieldMap FieldMap
+
+	// Write the caller as a single `caller` field, see `Entry.Caller`,
+	// instead of separate `filename`, `line` and `func` fields.
+	JoinCaller bool
+
+	// Control characters in keys, values and messages, such as newlines and
+	// the escape character starting ANSI sequences, are escaped so they
+	// can't forge log lines or take over a terminal. Disable it only when
+	// everything logged is trusted. Values and keys needing quotes, such as
+	// those with spaces, `=` or quotes, are quoted like Go strings either
+	// way, so they can't forge other fields.
+	DisableEscaping bool
 }
 
 func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 	var b *bytes.Buffer
-	var keys []string = make([]string, 0, len(entry.Data))
-	for k := range entry.Data {
+
+	// The fields may be shared with other entries.
+	data := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	isColored := f.isColored(entry)
+	if isColored {
+		prefixFieldClashes(data, f.FieldMap)
+	} else {
+		prefixFieldClashes(data, f.FieldMap, callerKeys(entry, f.JoinCaller)...)
+	}
+
+	var keys []string = make([]string, 0, len(data))
+	for k := range data {
 		keys = append(keys, k)
 	}
 
@@ -72,42 +112,91 @@ func (f *TextFormatter) Format(entry *Entry) ([]byte, error) {
 		b = &bytes.Buffer{}
 	}
 
-	prefixFieldClashes(entry.Data)
-
-	isColorTerminal := isTerminal && (runtime.GOOS != "windows")
-	isColored := (f.ForceColors || isColorTerminal) && !f.DisableColors
-
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
 		timestampFormat = DefaultTimestampFormat
 	}
 	if isColored {
-		f.printColored(b, entry, keys, timestampFormat)
+		f.printColored(b, entry, data, keys, timestampFormat)
 	} else {
 		if !f.DisableTimestamp {
-			f.appendKeyValue(b, "time", entry.Time.Format(timestampFormat))
+			f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyTime), entry.Time.Format(timestampFormat))
 		}
-		f.appendKeyValue(b, "level", entry.Level.String())
+		f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyLevel), entry.Level.String())
 
-		f.appendKeyValue(b, "filename", entry.FileName)
-		f.appendKeyValue(b, "line", entry.Line)
+		if entry.FileName != "" {
+			if f.JoinCaller {
+				f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyCaller), entry.Caller())
+			} else {
+				f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyFileName), entry.FileName)
+				f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyLine), entry.Line)
+				if entry.Func != "" {
+					f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyFunc), entry.Func)
+				}
+			}
+		}
 
 		if entry.Message != "" {
-			f.appendKeyValue(b, "message", entry.Message)
+			f.appendKeyValue(b, f.FieldMap.resolve(FieldKeyMsg), entry.Message)
 		}
 		for _, key := range keys {
-			f.appendKeyValue(b, key, entry.Data[key])
+			f.appendKeyValue(b, key, data[key])
 		}
 	}
+	f.appendStack(b, entry.Stack)
 
 	b.WriteByte('\n')
 	return b.Bytes(), nil
 }
 
-func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []string, timestampFormat string) {
+// isColored returns whether the entry should be printed with colors. The
+// formatter's settings come first, then the NO_COLOR, FORCE_COLOR and TERM
+// environment variables, then whether the logger's output is a TTY.
+func (f *TextFormatter) isColored(entry *Entry) bool {
+	switch {
+	case f.DisableColors:
+		return false
+	case f.ForceColors:
+		return true
+	case envNoColor:
+		return false
+	case envForceColor:
+		return true
+	case entry.Logger == nil:
+		return false
+	}
+
+	logger := entry.Logger.base()
+	logger.mu.Lock()
+	out := logger.Out
+	logger.mu.Unlock()
+
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+
+	f.terminalMu.Lock()
+	defer f.terminalMu.Unlock()
+	isTerminal, ok := f.terminalFiles[file]
+	if !ok {
+		// Forget the files of previous outputs, which may be closed.
+		if len(f.terminalFiles) >= maxTerminalFiles {
+			f.terminalFiles = nil
+		}
+		if f.terminalFiles == nil {
+			f.terminalFiles = make(map[*os.File]bool)
+		}
+		isTerminal = isColorTerminal(file)
+		f.terminalFiles[file] = isTerminal
+	}
+	return isTerminal
+}
+
+func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, data Fields, keys []string, timestampFormat string) {
 	var levelColor int
 	switch entry.Level {
-	case DebugLevel:
+	case DebugLevel, TraceLevel:
 		levelColor = gray
 	case WarnLevel:
 		levelColor = yellow
@@ -119,14 +208,23 @@ func (f *TextFormatter) printColored(b *bytes.Buffer, entry *Entry, keys []strin
 
 	levelText := strings.ToUpper(entry.Level.String())[0:4]
 
+	message := f.escape(entry.Message)
 	if !f.FullTimestamp {
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%04d] %-44s ", levelColor, levelText, miniTS(), entry.Message)
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%04d] %-44s ", levelColor, levelText, miniTS(), message)
 	} else {
-		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%s] %-44s ", levelColor, levelText, entry.Time.Format(timestampFormat), entry.Message)
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m[%s] %-44s ", levelColor, levelText, entry.Time.Format(timestampFormat), message)
 	}
 	for _, k := range keys {
-		v := entry.Data[k]
-		fmt.Fprintf(b, " \x1b[%dm%s\x1b[0m=%+v", levelColor, k, v)
+		v := data[k]
+		fmt.Fprintf(b, " \x1b[%dm%s\x1b[0m=%s", levelColor, f.formatKey(k), f.escape(fmt.Sprintf("%+v", v)))
+	}
+}
+
+// appendStack writes the stack trace on the lines following the entry, in the
+// layout of a Go panic.
+func (f *TextFormatter) appendStack(b *bytes.Buffer, stack []Frame) {
+	for _, frame := range stack {
+		fmt.Fprintf(b, "\n\t%s\n\t\t%s:%d", f.escape(frame.Func), f.escape(frame.File), frame.Line)
 	}
 }
 
@@ -143,27 +241,88 @@ func needsQuoting(text string) bool {
 }
 
 func (f *TextFormatter) appendKeyValue(b *bytes.Buffer, key string, value interface{}) {
-
-	b.WriteString(key)
+	b.WriteString(f.formatKey(key))
 	b.WriteByte('=')
 
 	switch value := value.(type) {
 	case string:
-		if !needsQuoting(value) {
-			b.WriteString(value)
-		} else {
-			fmt.Fprintf(b, "%q", value)
-		}
+		f.appendString(b, value)
 	case error:
-		errmsg := value.Error()
-		if !needsQuoting(errmsg) {
-			b.WriteString(errmsg)
-		} else {
-			fmt.Fprintf(b, "%q", value)
-		}
+		f.appendString(b, value.Error())
 	default:
-		fmt.Fprint(b, value)
+		// Such as slices or structs, whose spaces and `=` could forge fields.
+		f.appendString(b, fmt.Sprint(value))
 	}
 
 	b.WriteByte(' ')
 }
+
+func (f *TextFormatter) appendString(b *bytes.Buffer, s string) {
+	if !needsQuoting(s) {
+		b.WriteString(s)
+	} else {
+		b.WriteString(strconv.Quote(s))
+	}
+}
+
+// formatKey returns key quoted if it has a space, `=` or quote, which would
+// make the rest of it read as another field, or control characters to escape.
+func (f *TextFormatter) formatKey(key string) string {
+	if key != "" && !strings.ContainsAny(key, " =\"") && (f.DisableEscaping || !hasControl(key)) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// escape escapes the control characters of s unless DisableEscaping is set.
+// Strings without control characters are returned as is.
+func (f *TextFormatter) escape(s string) string {
+	if f.DisableEscaping || !hasControl(s) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x80 && isControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case isControl(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// hasControl returns whether s has control characters or invalid UTF-8, whose
+// bytes may be taken for 8-bit control characters by terminals.
+func hasControl(s string) bool {
+	for i, r := range s {
+		if isControl(r) {
+			return true
+		}
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isControl returns whether r is a C0 or C1 control character, DEL, or a
+// Unicode line or paragraph separator.
+func isControl(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f) || r == '\u2028' || r == '\u2029'
+}
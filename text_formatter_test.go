@@ -0,0 +1,158 @@
+package logrus
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func formatText(t *testing.T, f *TextFormatter, msg string, data Fields) string {
+	t.Helper()
+	entry := NewEntry(New())
+	entry.Level = InfoLevel
+	entry.Message = msg
+	for k, v := range data {
+		entry.Data[k] = v
+	}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSuffix(string(b), "\n")
+}
+
+func TestTextFormatterKeys(t *testing.T) {
+	f := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	for _, tt := range []struct {
+		key, want string
+	}{
+		{"user", `user=bob `},
+		{"user_id", `user_id=bob `},
+		{"a b", `"a b"=bob `},
+		{"x level=error y", `"x level=error y"=bob `},
+		{`say"hi`, `"say\"hi"=bob `},
+		{"a\nlevel", `"a\nlevel"=bob `},
+		{"\x1b[31m", `"\x1b[31m"=bob `},
+		{"", `""=bob `},
+	} {
+		got := formatText(t, f, "", Fields{tt.key: "bob"})
+		if want := "level=info " + tt.want; got != want {
+			t.Errorf("key %q formatted as %q, want %q", tt.key, got, want)
+		}
+	}
+}
+
+func TestTextFormatterValues(t *testing.T) {
+	f := &TextFormatter{DisableColors: true, DisableTimestamp: true}
+	for _, tt := range []struct {
+		value interface{}
+		want  string
+	}{
+		{"bob", `k=bob `},
+		{"two words", `k="two words" `},
+		{"a=b", `k="a=b" `},
+		{"line\nlevel=error", `k="line\nlevel=error" `},
+		{errors.New("x\ty"), `k="x\ty" `},
+		{42, `k=42 `},
+		{[]string{"a\nb"}, `k="[a\nb]" `},
+		{[]string{"a level=panic"}, `k="[a level=panic]" `},
+		{struct{ A string }{"x y"}, `k="{x y}" `},
+	} {
+		got := formatText(t, f, "", Fields{"k": tt.value})
+		if want := "level=info " + tt.want; got != want {
+			t.Errorf("value %#v formatted as %q, want %q", tt.value, got, want)
+		}
+	}
+}
+
+func TestTextFormatterDisableEscaping(t *testing.T) {
+	f := &TextFormatter{DisableColors: true, DisableTimestamp: true, DisableEscaping: true}
+	got := formatText(t, f, "two\nlines", Fields{
+		"plain": "bob",
+		"raw":   "a\tb \"c\"",
+		"err":   errors.New("x\ny"),
+		"a\nb":  "key",
+		"a b":   "key",
+	})
+	want := `level=info message="two\nlines" ` + "a\nb=key" + ` "a b"=key err="x\ny" plain=bob raw="a\tb \"c\"" `
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestTextFormatterColoredKeys(t *testing.T) {
+	f := &TextFormatter{ForceColors: true}
+	got := formatText(t, f, "msg\x1b[2J", Fields{"a b\n": "v\r"})
+	if !strings.Contains(got, `msg\x1b[2J`) || !strings.Contains(got, `"a b\n"`) || !strings.Contains(got, `=v\r`) {
+		t.Errorf("Format() = %q", got)
+	}
+}
+
+func TestTextFormatterTerminalPerOutput(t *testing.T) {
+	if envNoColor || envForceColor {
+		t.Skip("colors are set by the environment")
+	}
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil || !isColorTerminal(tty) {
+		t.Skip("no pseudo terminal")
+	}
+	defer tty.Close()
+	file, err := ioutil.TempFile(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	f := &TextFormatter{}
+	terminal, plain := New(), New()
+	terminal.Out, plain.Out = tty, file
+
+	if !f.isColored(NewEntry(terminal)) {
+		t.Error("not colored on a terminal")
+	}
+	if f.isColored(NewEntry(plain)) {
+		t.Error("colored on a file by a formatter shared with a terminal logger")
+	}
+	terminal.Out = file
+	if f.isColored(NewEntry(terminal)) {
+		t.Error("still colored after the output changed to a file")
+	}
+	terminal.Out = tty
+	if !f.isColored(NewEntry(terminal)) {
+		t.Error("not colored after the output changed back to the terminal")
+	}
+}
+
+func TestTextFormatterFieldClashes(t *testing.T) {
+	f := &TextFormatter{
+		DisableColors:    true,
+		DisableTimestamp: true,
+		FieldMap:         FieldMap{FieldKeyLevel: "severity", FieldKeyMsg: "msg"},
+	}
+	entry := NewEntry(New())
+	entry.Level = InfoLevel
+	entry.Message = "hello"
+	entry.Data = Fields{"severity": "x", "msg": "y", "level": "z"}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "severity=info msg=hello fields.msg=y fields.severity=x level=z \n"
+	if string(b) != want {
+		t.Errorf("Format() = %q, want %q", b, want)
+	}
+	if len(entry.Data) != 3 || entry.Data["severity"] != "x" {
+		t.Errorf("Format() changed the fields of the entry: %v", entry.Data)
+	}
+}
+
+func TestTextFormatterColoredDisableEscaping(t *testing.T) {
+	f := &TextFormatter{ForceColors: true, DisableEscaping: true}
+	got := formatText(t, f, "bold \x1b[1mtext", Fields{"k\t": "v"})
+	if !strings.Contains(got, "bold \x1b[1mtext") || !strings.Contains(got, "k\t\x1b[0m=v") {
+		t.Errorf("Format() = %q, want the message and key as they are", got)
+	}
+}
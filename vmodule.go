@@ -0,0 +1,160 @@
+package logrus
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// vmodule holds per-file level rules, in the spirit of glog's `-vmodule`
+// flag. A spec such as `db/*=debug,http.go=warn` logs debug entries from the
+// files of any `db` directory and only warnings and above from `http.go`,
+// while every other file uses the logger level.
+//
+// A pattern without a slash is matched against the base name of the file, a
+// pattern with slashes against as many trailing path elements. Patterns use
+// `path.Match` syntax and match with or without the `.go` extension. The first
+// matching rule wins.
+type vmodule struct {
+	spec  string
+	rules []vmoduleRule
+	// The most verbose level of all rules, to keep the per-level checks cheap.
+	max Level
+
+	// Rule lookups are cached per call site.
+	mu    sync.RWMutex
+	cache map[uintptr]vmoduleMatch
+}
+
+type vmoduleRule struct {
+	pattern  string
+	elements int
+	level    Level
+}
+
+type vmoduleMatch struct {
+	level Level
+	ok    bool
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	vm := &vmodule{spec: spec, cache: make(map[uintptr]vmoduleMatch)}
+	for _, r := range strings.Split(spec, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		eq := strings.LastIndex(r, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("not a valid vmodule rule: %q", r)
+		}
+		pattern := strings.TrimSpace(r[:eq])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("not a valid vmodule pattern: %q", pattern)
+		}
+		level, err := ParseLevel(strings.TrimSpace(r[eq+1:]))
+		if err != nil {
+			return nil, err
+		}
+		vm.rules = append(vm.rules, vmoduleRule{
+			pattern:  pattern,
+			elements: strings.Count(pattern, "/") + 1,
+			level:    level,
+		})
+		if level > vm.max {
+			vm.max = level
+		}
+	}
+	return vm, nil
+}
+
+// level returns the level of the first rule matching file, which is the
+// caller at pc.
+func (vm *vmodule) level(pc uintptr, file string) (Level, bool) {
+	vm.mu.RLock()
+	m, cached := vm.cache[pc]
+	vm.mu.RUnlock()
+	if cached {
+		return m.level, m.ok
+	}
+
+	for _, r := range vm.rules {
+		if r.match(file) {
+			m = vmoduleMatch{level: r.level, ok: true}
+			break
+		}
+	}
+
+	vm.mu.Lock()
+	vm.cache[pc] = m
+	vm.mu.Unlock()
+	return m.level, m.ok
+}
+
+func (r vmoduleRule) match(file string) bool {
+	name := lastElements(file, r.elements)
+	if ok, _ := path.Match(r.pattern, name); ok {
+		return true
+	}
+	ok, _ := path.Match(r.pattern, strings.TrimSuffix(name, ".go"))
+	return ok
+}
+
+// lastElements returns the last n slash separated elements of file.
+func lastElements(file string, n int) string {
+	i := len(file)
+	for ; n > 0; n-- {
+		i = strings.LastIndex(file[:i], "/")
+		if i < 0 {
+			return file
+		}
+	}
+	return file[i+1:]
+}
+
+// SetVModule sets per-file level rules such as `db/*=debug,http.go=warn`.
+// Entries logged from a file matching a rule use the rule level instead of
+// the logger level. An empty spec removes all rules.
+func (logger *Logger) SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	logger.base().vmodule.Store(vm)
+	return nil
+}
+
+// SetVModule sets per-file level rules on the standard logger.
+func SetVModule(spec string) error {
+	return std.SetVModule(spec)
+}
+
+// GetVModule returns the spec of the per-file level rules.
+func (logger *Logger) GetVModule() string {
+	if vm := logger.getVModule(); vm != nil {
+		return vm.spec
+	}
+	return ""
+}
+
+func (logger *Logger) getVModule() *vmodule {
+	vm, _ := logger.base().vmodule.Load().(*vmodule)
+	if vm == nil || len(vm.rules) == 0 {
+		return nil
+	}
+	return vm
+}
+
+// callerEnabled makes the final level decision once `Entry.log` knows the
+// caller. Only rules can disable a level the per-level checks let through.
+func (logger *Logger) callerEnabled(level Level, pc uintptr, file string) bool {
+	vm := logger.getVModule()
+	if vm == nil {
+		return true
+	}
+	if l, ok := vm.level(pc, file); ok {
+		return level <= l
+	}
+	return level <= logger.level()
+}
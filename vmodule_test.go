@@ -0,0 +1,55 @@
+package logrus
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVModule(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.Out = &buf
+	logger.Formatter = &TextFormatter{DisableTimestamp: true, DisableColors: true}
+
+	if err := logger.SetVModule("vmodule_test.go=debug,other.go=error"); err != nil {
+		t.Fatal(err)
+	}
+	if logger.IsLevelEnabled(DebugLevel) {
+		t.Error("IsLevelEnabled(debug) = true with the logger at info")
+	}
+	if !logger.IsCallerLevelEnabled(DebugLevel) {
+		t.Error("IsCallerLevelEnabled(debug) = false from a file with a debug rule")
+	}
+	if logger.IsCallerLevelEnabled(TraceLevel) {
+		t.Error("IsCallerLevelEnabled(trace) = true from a file with a debug rule")
+	}
+
+	logger.Debug("enabled by the rule")
+	logger.Trace("disabled")
+	if s := buf.String(); !strings.Contains(s, "enabled by the rule") || strings.Contains(s, "disabled") {
+		t.Errorf("logged %q", s)
+	}
+
+	if err := logger.SetVModule("vmodule_test=warn"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	logger.Info("disabled")
+	if buf.Len() != 0 {
+		t.Errorf("logged %q despite a warn rule", buf.String())
+	}
+	if !logger.IsLevelEnabled(InfoLevel) || logger.IsCallerLevelEnabled(InfoLevel) {
+		t.Error("a warn rule should only disable info for its files")
+	}
+
+	if err := logger.SetVModule("db/*=loud"); err == nil {
+		t.Error("SetVModule accepted an invalid level")
+	}
+	if err := logger.SetVModule(""); err != nil {
+		t.Fatal(err)
+	}
+	if !logger.IsCallerLevelEnabled(InfoLevel) || logger.IsCallerLevelEnabled(DebugLevel) {
+		t.Error("the logger level should apply without rules")
+	}
+}